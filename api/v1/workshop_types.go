@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -31,6 +32,44 @@ type WorkshopSpec struct {
 	User           UserSpec           `json:"user"`
 	Source         SourceSpec         `json:"source"`
 	Infrastructure InfrastructureSpec `json:"infrastructure"`
+	// AppsDomain overrides the apps domain discovered from the cluster Ingress config.
+	// It is required on Kubernetes, where Ingress hosts are <name>-<namespace>.<appsDomain>
+	AppsDomain string `json:"appsDomain,omitempty"`
+	// IngressClassName is the class of the Ingresses created on Kubernetes,
+	// the default IngressClass of the cluster is used if empty
+	IngressClassName string `json:"ingressClassName,omitempty"`
+	// Prefix is prepended to the namespaces, staging projects and cluster-scoped resources
+	// of the Workshop, so that several Workshops can run on the same cluster
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=20
+	Prefix string `json:"prefix,omitempty"`
+	// Schedule provisions the Workshop ahead of its start and tears it down at its end
+	Schedule ScheduleSpec `json:"schedule,omitempty"`
+	// Hibernate scales the workloads of the Workshop to zero, they are restored when it is disabled
+	Hibernate HibernateSpec `json:"hibernate,omitempty"`
+}
+
+// HibernateSpec ...
+type HibernateSpec struct {
+	Enabled bool `json:"enabled"`
+	// UserWorkloads also scales the Deployments and StatefulSets of the user projects
+	UserWorkloads bool `json:"userWorkloads,omitempty"`
+}
+
+// ScheduleSpec ...
+type ScheduleSpec struct {
+	// Start of the Workshop, nothing is provisioned before Start minus ProvisionLeadTime
+	Start *metav1.Time `json:"start,omitempty"`
+	// End of the Workshop, when EndAction is run
+	End *metav1.Time `json:"end,omitempty"`
+	// EndAction defaults to RevokeProjectAccess, which removes the access of the users to their projects
+	// and stops the portal. Their Gitea, Argo CD, CodeReady and Vault credentials are kept,
+	// and both are restored if End is moved later.
+	// Delete runs the finalizer of the Workshop and is only done when set explicitly.
+	// +kubebuilder:validation:Enum=Delete;Hibernate;RevokeProjectAccess
+	EndAction string `json:"endAction,omitempty"`
+	// ProvisionLeadTime defaults to 2h
+	ProvisionLeadTime *metav1.Duration `json:"provisionLeadTime,omitempty"`
 }
 
 // UserSpec ...
@@ -71,41 +110,149 @@ type BookbagSpec struct {
 type CertManagerSpec struct {
 	Enabled     bool            `json:"enabled"`
 	OperatorHub OperatorHubSpec `json:"operatorHub"`
+	Issuer      CertIssuerSpec  `json:"issuer,omitempty"`
+	// Namespace of the cert-manager instance on OpenShift, defaults to cert-manager
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// CertIssuerSpec ...
+type CertIssuerSpec struct {
+	// Type of the ClusterIssuer signing the route certificates, defaults to SelfSigned
+	// +kubebuilder:validation:Enum=SelfSigned;ACME
+	Type string         `json:"type,omitempty"`
+	ACME ACMEIssuerSpec `json:"acme,omitempty"`
+}
+
+// ACMEIssuerSpec ...
+type ACMEIssuerSpec struct {
+	// Server is the ACME directory URL, e.g. https://pebble.pebble.svc:14000/dir
+	Server string `json:"server"`
+	Email  string `json:"email,omitempty"`
+	// SkipTLSVerify is required by local ACME servers like Pebble
+	SkipTLSVerify bool `json:"skipTLSVerify,omitempty"`
 }
 
 // GiteaSpec ...
 type GiteaSpec struct {
 	Enabled bool      `json:"enabled"`
-	Image   ImageSpec `json:"image"`
+	Image   ImageSpec `json:"image,omitempty"`
+	// Namespace defaults to gitea
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // GitOpsSpec ...
 type GitOpsSpec struct {
-	Enabled     bool            `json:"enabled"`
+	Enabled bool `json:"enabled"`
+	// OperatorHub refers to the argocd-operator package of the OperatorHub.io catalog outside of OpenShift
 	OperatorHub OperatorHubSpec `json:"operatorHub"`
+	// UseDefaultInstance manages the AppProjects and RBAC of the openshift-gitops instance
+	// provisioned by OpenShift GitOps instead of creating an argocd instance
+	UseDefaultInstance bool `json:"useDefaultInstance,omitempty"`
+	// Namespace of the argocd instance, defaults to argocd
+	Namespace string `json:"namespace,omitempty"`
+	// OpenShiftOAuth logs the users in through Dex with their OpenShift account
+	// instead of local Argo CD accounts
+	OpenShiftOAuth bool `json:"openshiftOAuth,omitempty"`
+	// AdminGroups are the OpenShift groups granted the Argo CD admin role with OpenShiftOAuth
+	AdminGroups []string              `json:"adminGroups,omitempty"`
+	Application GitOpsApplicationSpec `json:"application,omitempty"`
+	AppOfApps   GitOpsAppOfAppsSpec   `json:"appOfApps,omitempty"`
+}
+
+// GitOpsApplicationSpec is the template of the Application created for each user
+// in each of its projects managed by Argo CD
+type GitOpsApplicationSpec struct {
+	Enabled bool `json:"enabled"`
+	// Repository of the user in Gitea, i.e. http://gitea-server.gitea.svc:3000/<user>/<repository>
+	Repository string `json:"repository,omitempty"`
+	// Path defaults to the repository root
+	Path string `json:"path,omitempty"`
+	// TargetRevision defaults to HEAD
+	TargetRevision string `json:"targetRevision,omitempty"`
+	// AutomatedSync enables the automated sync with prune and self heal
+	AutomatedSync bool `json:"automatedSync,omitempty"`
+}
+
+// GitOpsAppOfAppsSpec is an Application deploying the Applications found in a repository
+type GitOpsAppOfAppsSpec struct {
+	Enabled       bool   `json:"enabled"`
+	RepositoryURL string `json:"repositoryURL,omitempty"`
+	// Path defaults to the repository root
+	Path string `json:"path,omitempty"`
+	// TargetRevision defaults to HEAD
+	TargetRevision string `json:"targetRevision,omitempty"`
 }
 
 // GuideSpec ...
 type GuideSpec struct {
 	Bookbag  BookbagSpec  `json:"bookbag,omitempty"`
 	Scholars ScholarsSpec `json:"scholars,omitempty"`
+	// Namespace of the Bookbag guides, defaults to workshop-guides
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // NexusSpec ...
 type NexusSpec struct {
-	Enabled bool `json:"enabled"`
+	Enabled bool      `json:"enabled"`
+	Image   ImageSpec `json:"image,omitempty"`
+	// Namespace defaults to opentlc-shared
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // PipelineSpec ...
 type PipelineSpec struct {
-	Enabled     bool            `json:"enabled"`
+	Enabled bool `json:"enabled"`
+	// OperatorHub refers to the tektoncd-operator package of the OperatorHub.io catalog outside of OpenShift
 	OperatorHub OperatorHubSpec `json:"operatorHub"`
 }
 
 // ProjectSpec ...
 type ProjectSpec struct {
-	Enabled     bool   `json:"enabled"`
-	StagingName string `json:"stagingName"`
+	Enabled bool `json:"enabled"`
+	// StagingName creates a single project <StagingName><N> per user when Templates is empty
+	StagingName string `json:"stagingName,omitempty"`
+	// Templates declares the projects created for every user
+	Templates []ProjectTemplateSpec `json:"templates,omitempty"`
+	// ResourceQuota is applied to the projects and the workspace namespaces of the users
+	ResourceQuota *corev1.ResourceQuotaSpec `json:"resourceQuota,omitempty"`
+	// LimitRange sets the default requests and limits in the same namespaces
+	LimitRange *corev1.LimitRangeSpec `json:"limitRange,omitempty"`
+	// Isolation denies the incoming traffic from the other projects to the user projects,
+	// except from the router, the monitoring, the mesh control plane and the Workshop components
+	Isolation bool `json:"isolation,omitempty"`
+	// Seed applies manifests to every user project
+	Seed SeedSpec `json:"seed,omitempty"`
+}
+
+// SeedSpec references the manifests applied to every user project. They are rendered as Go templates
+// with {{.Username}}, {{.UserID}}, {{.Project}}, {{.AppsHostnameSuffix}} and {{.GiteaURL}}.
+// The operator must be allowed to manage the kinds of the manifests, which must be namespaced.
+// The objects which are no longer rendered are deleted, as well as all of them when the seed is disabled
+type SeedSpec struct {
+	Enabled bool `json:"enabled"`
+	// Path of the directory of manifests in the repository of the Source, which must be hosted on GitHub,
+	// otherwise the SeedSourceSupported condition is false and no manifest is applied.
+	// The manifests are fetched again when the branch moves to another commit, checked every 10 minutes
+	Path string `json:"path,omitempty"`
+	// ConfigMap in the namespace of the Workshop holding one manifest per key, used instead of Path
+	ConfigMap string `json:"configMap,omitempty"`
+}
+
+// ProjectTemplateSpec ...
+type ProjectTemplateSpec struct {
+	// Name of the project, a Go template rendered with {{.Username}} and {{.UserID}}, like dev-{{.Username}}.
+	// The rendered name, with the prefix, must be a valid DNS label
+	// +kubebuilder:validation:Pattern=`\{\{\.(Username|UserID)\}\}`
+	Name string `json:"name"`
+	// DisplayName of the project, a Go template rendered like Name
+	DisplayName string `json:"displayName,omitempty"`
+	// UserRole is the ClusterRole granted to the user in the project, defaults to edit
+	UserRole string            `json:"userRole,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	// ServiceMesh adds the project to the control plane of the user
+	ServiceMesh bool `json:"serviceMesh,omitempty"`
+	// GitOps lets Argo CD deploy the applications of the user in the project
+	GitOps bool `json:"gitops,omitempty"`
 }
 
 // ScholarsSpec ...
@@ -116,17 +263,83 @@ type ScholarsSpec struct {
 
 // ServiceMeshSpec ...
 type ServiceMeshSpec struct {
-	Enabled                  bool            `json:"enabled"`
-	ServiceMeshOperatorHub   OperatorHubSpec `json:"serviceMeshOperatorHub"`
-	ElasticSearchOperatorHub OperatorHubSpec `json:"elasticSearchOperatorHub"`
-	JaegerOperatorHub        OperatorHubSpec `json:"jaegerOperatorHub"`
-	KialiOperatorHub         OperatorHubSpec `json:"kialiOperatorHub"`
+	Enabled                  bool             `json:"enabled"`
+	ServiceMeshOperatorHub   OperatorHubSpec  `json:"serviceMeshOperatorHub"`
+	ElasticSearchOperatorHub OperatorHubSpec  `json:"elasticSearchOperatorHub"`
+	JaegerOperatorHub        OperatorHubSpec  `json:"jaegerOperatorHub"`
+	KialiOperatorHub         OperatorHubSpec  `json:"kialiOperatorHub"`
+	ControlPlane             ControlPlaneSpec `json:"controlPlane,omitempty"`
+	UserRouting              UserRoutingSpec  `json:"userRouting,omitempty"`
+	// Namespace of the shared control plane, defaults to istio-system
+	Namespace string `json:"namespace,omitempty"`
+	// Tenancy is either a control plane shared by all users in the namespace
+	// or a control plane per user in <user>-istio-system, defaults to Shared
+	// +kubebuilder:validation:Enum=Shared;PerUser
+	Tenancy string `json:"tenancy,omitempty"`
+}
+
+// UserRoutingSpec declares the Istio objects created in each user project of the mesh.
+// Templates are rendered with {{.Username}}, {{.Project}}, {{.Host}} and {{.AppsHostnameSuffix}}
+type UserRoutingSpec struct {
+	Enabled bool `json:"enabled"`
+	// Gateway template, defaults to an HTTP Gateway on the user host
+	Gateway         string `json:"gateway,omitempty"`
+	VirtualService  string `json:"virtualService,omitempty"`
+	DestinationRule string `json:"destinationRule,omitempty"`
+}
+
+// ControlPlaneSpec ...
+type ControlPlaneSpec struct {
+	// Version of the control plane, defaults to v2.0
+	Version string `json:"version,omitempty"`
+	// TracingSampling in hundredths of a percent (10000 is 100%), defaults to 10000
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10000
+	TracingSampling *int32       `json:"tracingSampling,omitempty"`
+	Jaeger          JaegerSpec   `json:"jaeger,omitempty"`
+	Gateways        GatewaysSpec `json:"gateways,omitempty"`
+	Addons          AddonsSpec   `json:"addons,omitempty"`
+}
+
+// JaegerSpec ...
+type JaegerSpec struct {
+	// StorageType defaults to Memory
+	// +kubebuilder:validation:Enum=Memory;Elasticsearch
+	StorageType string `json:"storageType,omitempty"`
+	// ElasticsearchNodeCount defaults to 1
+	ElasticsearchNodeCount int32 `json:"elasticsearchNodeCount,omitempty"`
+	// ElasticsearchStorageSize defaults to 10Gi
+	ElasticsearchStorageSize string `json:"elasticsearchStorageSize,omitempty"`
+	// ElasticsearchRetentionDays defaults to 7
+	ElasticsearchRetentionDays int32 `json:"elasticsearchRetentionDays,omitempty"`
+}
+
+// GatewaysSpec ...
+type GatewaysSpec struct {
+	// IngressReplicas defaults to 1
+	IngressReplicas int32 `json:"ingressReplicas,omitempty"`
+	// Egress gateway, defaults to true
+	Egress *bool `json:"egress,omitempty"`
+	// OpenShiftRoute creates Routes for the Gateway hosts, defaults to true
+	OpenShiftRoute *bool `json:"openshiftRoute,omitempty"`
+}
+
+// AddonsSpec ...
+type AddonsSpec struct {
+	// Grafana defaults to true
+	Grafana *bool `json:"grafana,omitempty"`
+	// Kiali defaults to true
+	Kiali *bool `json:"kiali,omitempty"`
+	// Prometheus defaults to true
+	Prometheus *bool `json:"prometheus,omitempty"`
 }
 
 // ServerlessSpec ...
 type ServerlessSpec struct {
 	Enabled     bool            `json:"enabled"`
 	OperatorHub OperatorHubSpec `json:"operatorHub"`
+	// Namespace of the Serverless Operator, defaults to openshift-serverless
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // CodeReadyWorkspaceSpec ...
@@ -135,6 +348,8 @@ type CodeReadyWorkspaceSpec struct {
 	OperatorHub         OperatorHubSpec `json:"operatorHub"`
 	OpenshiftOAuth      bool            `json:"openshiftOAuth"`
 	PluginRegistryImage ImageSpec       `json:"pluginRegistryImage,omitempty"`
+	// Namespace of the CodeReady Workspaces server, defaults to workspaces
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // IstioWorkspaceSpec ...
@@ -160,6 +375,18 @@ type VaultSpec struct {
 	Enabled            bool      `json:"enabled"`
 	Image              ImageSpec `json:"image"`
 	AgentInjectorImage ImageSpec `json:"agentInjectorImage"`
+	// HighAvailability runs Vault as a Raft cluster. The storage of an existing Vault is not migrated,
+	// so switching it between file and Raft is not applied and sets the VaultStorageValid condition to false.
+	HighAvailability VaultHighAvailabilitySpec `json:"highAvailability,omitempty"`
+	// Namespace defaults to vault
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// VaultHighAvailabilitySpec ...
+type VaultHighAvailabilitySpec struct {
+	Enabled bool `json:"enabled"`
+	// +kubebuilder:validation:Minimum=3
+	Replicas int32 `json:"replicas,omitempty"`
 }
 
 // WorkshopStatus defines the observed state of Workshop
@@ -180,6 +407,76 @@ type WorkshopStatus struct {
 	Serverless           string `json:"serverless"`
 	UsernameDistribution string `json:"usernameDistribution"`
 	Vault                string `json:"vault"`
+
+	Namespaces NamespacesStatus    `json:"namespaces,omitempty"`
+	Schedule   ScheduleStatus      `json:"schedule,omitempty"`
+	Conditions []WorkshopCondition `json:"conditions,omitempty"`
+	// Usage is the quota usage of every user, summed over their namespaces
+	Usage map[string]corev1.ResourceList `json:"usage,omitempty"`
+	// SeedKinds are the kinds of the seed objects applied to the user projects,
+	// listed to prune the objects which are no longer rendered
+	SeedKinds []string `json:"seedKinds,omitempty"`
+}
+
+// NamespacesStatus lists the namespaces of the components, resolved from the spec and the defaults
+type NamespacesStatus struct {
+	CertManager        string `json:"certManager,omitempty"`
+	CodeReadyWorkspace string `json:"codeReadyWorkspace,omitempty"`
+	Gitea              string `json:"gitea,omitempty"`
+	GitOps             string `json:"gitops,omitempty"`
+	Guide              string `json:"guide,omitempty"`
+	KnativeServing     string `json:"knativeServing,omitempty"`
+	Nexus              string `json:"nexus,omitempty"`
+	Serverless         string `json:"serverless,omitempty"`
+	ServiceMesh        string `json:"serviceMesh,omitempty"`
+	Vault              string `json:"vault,omitempty"`
+}
+
+// ScheduleStatus ...
+type ScheduleStatus struct {
+	// Phase is Scheduled, Provisioning, Running or Ended
+	Phase string `json:"phase,omitempty"`
+	// NextTransitionTime is the time of the next phase
+	NextTransitionTime *metav1.Time `json:"nextTransitionTime,omitempty"`
+	// TimeRemaining until the next phase
+	TimeRemaining string `json:"timeRemaining,omitempty"`
+}
+
+// WorkshopConditionType ...
+type WorkshopConditionType string
+
+const (
+	// WorkshopClusterDiscovered is true when the apps domain and the console URL are known
+	WorkshopClusterDiscovered WorkshopConditionType = "ClusterDiscovered"
+	// WorkshopHibernated is true when the workloads of the Workshop are scaled to zero
+	WorkshopHibernated WorkshopConditionType = "Hibernated"
+	// WorkshopProjectAccessRevoked is true when the users lost the access to their projects and the portal at the end of the Workshop
+	WorkshopProjectAccessRevoked WorkshopConditionType = "ProjectAccessRevoked"
+	// WorkshopProjectTemplatesValid is false when a project template renders an invalid namespace name
+	WorkshopProjectTemplatesValid WorkshopConditionType = "ProjectTemplatesValid"
+	// WorkshopCodeReadyWorkspaceSupported is false when CodeReady Workspaces is enabled on a platform without it
+	WorkshopCodeReadyWorkspaceSupported WorkshopConditionType = "CodeReadyWorkspaceSupported"
+	// WorkshopGitOpsSupported is false when the default instance or the OpenShift OAuth of GitOps is enabled outside of OpenShift
+	WorkshopGitOpsSupported WorkshopConditionType = "GitOpsSupported"
+	// WorkshopServerlessSupported is false when OpenShift Serverless is enabled on a platform without it
+	WorkshopServerlessSupported WorkshopConditionType = "ServerlessSupported"
+	// WorkshopServiceMeshSupported is false when OpenShift Service Mesh is enabled on a platform without it
+	WorkshopServiceMeshSupported WorkshopConditionType = "ServiceMeshSupported"
+	// WorkshopSeedSourceSupported is false when the seed manifests are fetched from a repository not hosted on GitHub
+	WorkshopSeedSourceSupported WorkshopConditionType = "SeedSourceSupported"
+	// WorkshopVaultInitialized is true when Vault is initialized and its keys are stored in the vault-unseal-keys Secret
+	WorkshopVaultInitialized WorkshopConditionType = "VaultInitialized"
+	// WorkshopVaultStorageValid is false when the high availability of an existing Vault is switched
+	WorkshopVaultStorageValid WorkshopConditionType = "VaultStorageValid"
+)
+
+// WorkshopCondition ...
+type WorkshopCondition struct {
+	Type               WorkshopConditionType  `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -21,9 +21,57 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ACMEIssuerSpec) DeepCopyInto(out *ACMEIssuerSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ACMEIssuerSpec.
+func (in *ACMEIssuerSpec) DeepCopy() *ACMEIssuerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ACMEIssuerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonsSpec) DeepCopyInto(out *AddonsSpec) {
+	*out = *in
+	if in.Grafana != nil {
+		in, out := &in.Grafana, &out.Grafana
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Kiali != nil {
+		in, out := &in.Kiali, &out.Kiali
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Prometheus != nil {
+		in, out := &in.Prometheus, &out.Prometheus
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonsSpec.
+func (in *AddonsSpec) DeepCopy() *AddonsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BookbagSpec) DeepCopyInto(out *BookbagSpec) {
 	*out = *in
@@ -40,10 +88,27 @@ func (in *BookbagSpec) DeepCopy() *BookbagSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertIssuerSpec) DeepCopyInto(out *CertIssuerSpec) {
+	*out = *in
+	out.ACME = in.ACME
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertIssuerSpec.
+func (in *CertIssuerSpec) DeepCopy() *CertIssuerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertIssuerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertManagerSpec) DeepCopyInto(out *CertManagerSpec) {
 	*out = *in
 	out.OperatorHub = in.OperatorHub
+	out.Issuer = in.Issuer
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerSpec.
@@ -73,10 +138,95 @@ func (in *CodeReadyWorkspaceSpec) DeepCopy() *CodeReadyWorkspaceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneSpec) DeepCopyInto(out *ControlPlaneSpec) {
+	*out = *in
+	if in.TracingSampling != nil {
+		in, out := &in.TracingSampling, &out.TracingSampling
+		*out = new(int32)
+		**out = **in
+	}
+	out.Jaeger = in.Jaeger
+	in.Gateways.DeepCopyInto(&out.Gateways)
+	in.Addons.DeepCopyInto(&out.Addons)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneSpec.
+func (in *ControlPlaneSpec) DeepCopy() *ControlPlaneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaysSpec) DeepCopyInto(out *GatewaysSpec) {
+	*out = *in
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = new(bool)
+		**out = **in
+	}
+	if in.OpenShiftRoute != nil {
+		in, out := &in.OpenShiftRoute, &out.OpenShiftRoute
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaysSpec.
+func (in *GatewaysSpec) DeepCopy() *GatewaysSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaysSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsAppOfAppsSpec) DeepCopyInto(out *GitOpsAppOfAppsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsAppOfAppsSpec.
+func (in *GitOpsAppOfAppsSpec) DeepCopy() *GitOpsAppOfAppsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsAppOfAppsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsApplicationSpec) DeepCopyInto(out *GitOpsApplicationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsApplicationSpec.
+func (in *GitOpsApplicationSpec) DeepCopy() *GitOpsApplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsApplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitOpsSpec) DeepCopyInto(out *GitOpsSpec) {
 	*out = *in
 	out.OperatorHub = in.OperatorHub
+	if in.AdminGroups != nil {
+		in, out := &in.AdminGroups, &out.AdminGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Application = in.Application
+	out.AppOfApps = in.AppOfApps
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsSpec.
@@ -122,6 +272,21 @@ func (in *GuideSpec) DeepCopy() *GuideSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HibernateSpec) DeepCopyInto(out *HibernateSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HibernateSpec.
+func (in *HibernateSpec) DeepCopy() *HibernateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HibernateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
 	*out = *in
@@ -143,13 +308,13 @@ func (in *InfrastructureSpec) DeepCopyInto(out *InfrastructureSpec) {
 	out.CertManager = in.CertManager
 	out.CodeReadyWorkspace = in.CodeReadyWorkspace
 	out.Gitea = in.Gitea
-	out.GitOps = in.GitOps
+	in.GitOps.DeepCopyInto(&out.GitOps)
 	in.Guide.DeepCopyInto(&out.Guide)
 	out.IstioWorkspace = in.IstioWorkspace
 	out.Nexus = in.Nexus
 	out.Pipeline = in.Pipeline
-	out.Project = in.Project
-	out.ServiceMesh = in.ServiceMesh
+	in.Project.DeepCopyInto(&out.Project)
+	in.ServiceMesh.DeepCopyInto(&out.ServiceMesh)
 	out.Serverless = in.Serverless
 	out.Vault = in.Vault
 }
@@ -180,9 +345,40 @@ func (in *IstioWorkspaceSpec) DeepCopy() *IstioWorkspaceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JaegerSpec) DeepCopyInto(out *JaegerSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JaegerSpec.
+func (in *JaegerSpec) DeepCopy() *JaegerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JaegerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacesStatus) DeepCopyInto(out *NamespacesStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacesStatus.
+func (in *NamespacesStatus) DeepCopy() *NamespacesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NexusSpec) DeepCopyInto(out *NexusSpec) {
 	*out = *in
+	out.Image = in.Image
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusSpec.
@@ -229,6 +425,24 @@ func (in *PipelineSpec) DeepCopy() *PipelineSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectSpec) DeepCopyInto(out *ProjectSpec) {
 	*out = *in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]ProjectTemplateSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceQuota != nil {
+		in, out := &in.ResourceQuota, &out.ResourceQuota
+		*out = new(corev1.ResourceQuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LimitRange != nil {
+		in, out := &in.LimitRange, &out.LimitRange
+		*out = new(corev1.LimitRangeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Seed = in.Seed
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectSpec.
@@ -241,6 +455,75 @@ func (in *ProjectSpec) DeepCopy() *ProjectSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectTemplateSpec) DeepCopyInto(out *ProjectTemplateSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectTemplateSpec.
+func (in *ProjectTemplateSpec) DeepCopy() *ProjectTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = (*in).DeepCopy()
+	}
+	if in.ProvisionLeadTime != nil {
+		in, out := &in.ProvisionLeadTime, &out.ProvisionLeadTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleSpec.
+func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleStatus) DeepCopyInto(out *ScheduleStatus) {
+	*out = *in
+	if in.NextTransitionTime != nil {
+		in, out := &in.NextTransitionTime, &out.NextTransitionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleStatus.
+func (in *ScheduleStatus) DeepCopy() *ScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScholarsSpec) DeepCopyInto(out *ScholarsSpec) {
 	*out = *in
@@ -263,6 +546,21 @@ func (in *ScholarsSpec) DeepCopy() *ScholarsSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedSpec) DeepCopyInto(out *SeedSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedSpec.
+func (in *SeedSpec) DeepCopy() *SeedSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SeedSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServerlessSpec) DeepCopyInto(out *ServerlessSpec) {
 	*out = *in
@@ -286,6 +584,8 @@ func (in *ServiceMeshSpec) DeepCopyInto(out *ServiceMeshSpec) {
 	out.ElasticSearchOperatorHub = in.ElasticSearchOperatorHub
 	out.JaegerOperatorHub = in.JaegerOperatorHub
 	out.KialiOperatorHub = in.KialiOperatorHub
+	in.ControlPlane.DeepCopyInto(&out.ControlPlane)
+	out.UserRouting = in.UserRouting
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMeshSpec.
@@ -313,6 +613,21 @@ func (in *SourceSpec) DeepCopy() *SourceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserRoutingSpec) DeepCopyInto(out *UserRoutingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserRoutingSpec.
+func (in *UserRoutingSpec) DeepCopy() *UserRoutingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UserRoutingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserSpec) DeepCopyInto(out *UserSpec) {
 	*out = *in
@@ -328,11 +643,27 @@ func (in *UserSpec) DeepCopy() *UserSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultHighAvailabilitySpec) DeepCopyInto(out *VaultHighAvailabilitySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultHighAvailabilitySpec.
+func (in *VaultHighAvailabilitySpec) DeepCopy() *VaultHighAvailabilitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultHighAvailabilitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultSpec) DeepCopyInto(out *VaultSpec) {
 	*out = *in
 	out.Image = in.Image
 	out.AgentInjectorImage = in.AgentInjectorImage
+	out.HighAvailability = in.HighAvailability
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSpec.
@@ -351,7 +682,7 @@ func (in *Workshop) DeepCopyInto(out *Workshop) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Workshop.
@@ -372,6 +703,22 @@ func (in *Workshop) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkshopCondition) DeepCopyInto(out *WorkshopCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkshopCondition.
+func (in *WorkshopCondition) DeepCopy() *WorkshopCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkshopCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkshopList) DeepCopyInto(out *WorkshopList) {
 	*out = *in
@@ -410,6 +757,8 @@ func (in *WorkshopSpec) DeepCopyInto(out *WorkshopSpec) {
 	out.User = in.User
 	out.Source = in.Source
 	in.Infrastructure.DeepCopyInto(&out.Infrastructure)
+	in.Schedule.DeepCopyInto(&out.Schedule)
+	out.Hibernate = in.Hibernate
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkshopSpec.
@@ -425,6 +774,37 @@ func (in *WorkshopSpec) DeepCopy() *WorkshopSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkshopStatus) DeepCopyInto(out *WorkshopStatus) {
 	*out = *in
+	out.Namespaces = in.Namespaces
+	in.Schedule.DeepCopyInto(&out.Schedule)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]WorkshopCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make(map[string]corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			var outVal map[corev1.ResourceName]resource.Quantity
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(corev1.ResourceList, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val.DeepCopy()
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.SeedKinds != nil {
+		in, out := &in.SeedKinds, &out.SeedKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkshopStatus.
@@ -11,11 +11,18 @@ import (
 
 // NewArgoCDCustomResource create a ArgoCD Custom Resource
 func NewArgoCDCustomResource(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
-	name string, namespace string, labels map[string]string, argocdPolicy string) *argocdoperator.ArgoCD {
+	name string, namespace string, labels map[string]string, argocdPolicy string, openshiftOAuth bool) *argocdoperator.ArgoCD {
 
 	scopes := "[preferred_username]"
 	defaultPolicy := ""
 
+	dex := argocdoperator.ArgoCDDexSpec{}
+	if openshiftOAuth {
+		// Groups are mapped to roles in the policy
+		scopes = "[groups,preferred_username]"
+		dex.OpenShiftOAuth = true
+	}
+
 	cr := &argocdoperator.ArgoCD{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -24,9 +31,7 @@ func NewArgoCDCustomResource(workshop *workshopv1.Workshop, scheme *runtime.Sche
 		},
 		Spec: argocdoperator.ArgoCDSpec{
 			ApplicationInstanceLabelKey: "argocd.argoproj.io/instance",
-			// Dex: argocd.ArgoCDDexSpec{
-			// 	OpenShiftOAuth: true,
-			// },
+			Dex:                         dex,
 			Server: argocdoperator.ArgoCDServerSpec{
 				Insecure: true,
 				Route: argocdoperator.ArgoCDRouteSpec{
@@ -73,3 +78,52 @@ func NewAppProjectCustomResource(workshop *workshopv1.Workshop, scheme *runtime.
 	ctrl.SetControllerReference(workshop, cr, scheme)
 	return cr
 }
+
+// NewApplicationCustomResource create an Application Custom Resource
+func NewApplicationCustomResource(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, project string,
+	repoURL string, path string, targetRevision string, destinationNamespace string, automatedSync bool) *argocd.Application {
+
+	if path == "" {
+		path = "."
+	}
+
+	if targetRevision == "" {
+		targetRevision = "HEAD"
+	}
+
+	var syncPolicy *argocd.SyncPolicy
+	if automatedSync {
+		syncPolicy = &argocd.SyncPolicy{
+			Automated: &argocd.SyncPolicyAutomated{
+				Prune:    true,
+				SelfHeal: true,
+			},
+		}
+	}
+
+	cr := &argocd.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: argocd.ApplicationSpec{
+			Project: project,
+			Source: argocd.ApplicationSource{
+				RepoURL:        repoURL,
+				Path:           path,
+				TargetRevision: targetRevision,
+			},
+			Destination: argocd.ApplicationDestination{
+				Namespace: destinationNamespace,
+				Server:    "https://kubernetes.default.svc",
+			},
+			SyncPolicy: syncPolicy,
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, cr, scheme)
+	return cr
+}
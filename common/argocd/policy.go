@@ -0,0 +1,36 @@
+package argocd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ApplicationControllerUser returns the user of the application controller service account of an Argo CD instance
+func ApplicationControllerUser(name string, namespace string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s-argocd-application-controller", namespace, name)
+}
+
+// MergePolicy replaces the named block managed by the operator in an existing RBAC policy
+// and keeps the rules defined by the instance owner or the other Workshops.
+// The block is removed if the managed policy is empty.
+func MergePolicy(policy string, managedPolicy string, blockName string) string {
+	policyBeginMarker := "# BEGIN " + blockName
+	policyEndMarker := "# END " + blockName
+
+	begin := strings.Index(policy, policyBeginMarker)
+	end := strings.Index(policy, policyEndMarker)
+	if begin >= 0 && end > begin {
+		policy = policy[:begin] + policy[end+len(policyEndMarker):]
+	}
+	policy = strings.TrimRight(policy, "\n")
+	if policy != "" {
+		policy += "\n"
+	}
+
+	// An empty managed policy removes the block
+	if managedPolicy == "" {
+		return policy
+	}
+
+	return policy + policyBeginMarker + "\n" + managedPolicy + policyEndMarker + "\n"
+}
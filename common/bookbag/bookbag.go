@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/nexus"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -17,6 +18,7 @@ func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	userID string, appsHostnameSuffix string, openshiftConsoleURL string) *appsv1.Deployment {
 
 	user := fmt.Sprintf("user%s", userID)
+	nexusNamespace := workshop.Status.Namespaces.Nexus
 	image := workshop.Spec.Infrastructure.Guide.Bookbag.Image.Name + ":" + workshop.Spec.Infrastructure.Guide.Bookbag.Image.Tag
 	consoleImage := "quay.io/openshift/origin-console:4.2"
 
@@ -25,12 +27,15 @@ func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	"APPS_HOSTNAME_SUFFIX": "` + appsHostnameSuffix + `",
 	"USER_ID": "` + userID + `",
 	"OPENSHIFT_PASSWORD": "` + workshop.Spec.User.Password + `",
-	"CHE_URL": "http://codeready-workspaces.` + appsHostnameSuffix + `",
-	"GIT_URL": "https://gitea-server-gitea.` + appsHostnameSuffix + `",
-	"JAEGER_URL": "https://jaeger-istio-system.` + appsHostnameSuffix + `",
-	"KIALI_URL": "https://kiali-istio-system.` + appsHostnameSuffix + `",
+	"CHE_URL": "http://codeready-` + workshop.Status.Namespaces.CodeReadyWorkspace + `.` + appsHostnameSuffix + `",
+	"GIT_URL": "https://gitea-server-` + workshop.Status.Namespaces.Gitea + `.` + appsHostnameSuffix + `",
+	"JAEGER_URL": "https://jaeger-` + workshop.Status.Namespaces.ServiceMesh + `.` + appsHostnameSuffix + `",
+	"KIALI_URL": "https://kiali-` + workshop.Status.Namespaces.ServiceMesh + `.` + appsHostnameSuffix + `",
 	"KIBANA_URL": "https://kibana-openshift-logging.` + appsHostnameSuffix + `",
-	"GITOPS_URL": "https://argocd-server-argocd.` + appsHostnameSuffix + `",
+	"GITOPS_URL": "https://argocd-server-` + workshop.Status.Namespaces.GitOps + `.` + appsHostnameSuffix + `",
+	"NEXUS_URL": "https://nexus-` + nexusNamespace + `.` + appsHostnameSuffix + `",
+	"NEXUS_MAVEN_URL": "` + nexus.MavenRepositoryURL(nexusNamespace) + `",
+	"NEXUS_NPM_URL": "` + nexus.NpmRepositoryURL(nexusNamespace) + `",
 	"WORKSHOP_GIT_REPO": "` + workshop.Spec.Source.GitURL + `",
 	"WORKSHOP_GIT_REF": "` + workshop.Spec.Source.GitBranch + `"
 }`
@@ -0,0 +1,48 @@
+package certmanager
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// NewCertificate create a Certificate issued by a ClusterIssuer
+func NewCertificate(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string,
+	dnsNames []string, secretName string, issuerName string) *Certificate {
+
+	certificate := &Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: CertificateSpec{
+			DNSNames:   dnsNames,
+			SecretName: secretName,
+			IssuerRef: ObjectReference{
+				Name:  issuerName,
+				Kind:  "ClusterIssuer",
+				Group: "cert-manager.io",
+			},
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, certificate, scheme)
+
+	return certificate
+}
+
+// NewCACertificate create a self-signed CA Certificate
+func NewCACertificate(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string,
+	commonName string, secretName string, issuerName string) *Certificate {
+
+	certificate := NewCertificate(workshop, scheme, name, namespace, labels, nil, secretName, issuerName)
+	certificate.Spec.CommonName = commonName
+	certificate.Spec.IsCA = true
+
+	return certificate
+}
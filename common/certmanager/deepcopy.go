@@ -33,3 +33,67 @@ func (in *CertManagerList) DeepCopyObject() runtime.Object {
 
 	return &out
 }
+
+// DeepCopyInto copies all properties of this object into another object of the
+// same type that is provided as a pointer.
+func (in *ClusterIssuer) DeepCopyInto(out *ClusterIssuer) {
+	out.TypeMeta = in.TypeMeta
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = in.Spec
+}
+
+// DeepCopyObject returns a generically typed copy of an object
+func (in *ClusterIssuer) DeepCopyObject() runtime.Object {
+	out := ClusterIssuer{}
+	in.DeepCopyInto(&out)
+
+	return &out
+}
+
+// DeepCopyObject returns a generically typed copy of an object
+func (in *ClusterIssuerList) DeepCopyObject() runtime.Object {
+	out := ClusterIssuerList{}
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+
+	if in.Items != nil {
+		out.Items = make([]ClusterIssuer, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+
+	return &out
+}
+
+// DeepCopyInto copies all properties of this object into another object of the
+// same type that is provided as a pointer.
+func (in *Certificate) DeepCopyInto(out *Certificate) {
+	out.TypeMeta = in.TypeMeta
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = in.Spec
+}
+
+// DeepCopyObject returns a generically typed copy of an object
+func (in *Certificate) DeepCopyObject() runtime.Object {
+	out := Certificate{}
+	in.DeepCopyInto(&out)
+
+	return &out
+}
+
+// DeepCopyObject returns a generically typed copy of an object
+func (in *CertificateList) DeepCopyObject() runtime.Object {
+	out := CertificateList{}
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+
+	if in.Items != nil {
+		out.Items = make([]Certificate, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+
+	return &out
+}
@@ -0,0 +1,68 @@
+package certmanager
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// NewSelfSignedClusterIssuer create a self-signed ClusterIssuer
+func NewSelfSignedClusterIssuer(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, labels map[string]string) *ClusterIssuer {
+
+	return newClusterIssuer(workshop, scheme, name, labels, IssuerSpec{
+		SelfSigned: &SelfSignedIssuer{},
+	})
+}
+
+// NewCAClusterIssuer create a ClusterIssuer signing with the CA stored in the secret
+func NewCAClusterIssuer(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, labels map[string]string, secretName string) *ClusterIssuer {
+
+	return newClusterIssuer(workshop, scheme, name, labels, IssuerSpec{
+		CA: &CAIssuer{
+			SecretName: secretName,
+		},
+	})
+}
+
+// NewACMEClusterIssuer create an ACME ClusterIssuer solving HTTP-01 challenges through the router
+func NewACMEClusterIssuer(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, labels map[string]string, server string, email string, skipTLSVerify bool) *ClusterIssuer {
+
+	return newClusterIssuer(workshop, scheme, name, labels, IssuerSpec{
+		ACME: &ACMEIssuer{
+			Server:        server,
+			Email:         email,
+			SkipTLSVerify: skipTLSVerify,
+			PrivateKeySecretRef: SecretKeySelector{
+				Name: name + "-account-key",
+			},
+			Solvers: []ACMEChallengeSolver{
+				{
+					HTTP01: &ACMEChallengeSolverHTTP01{
+						Ingress: &ACMEChallengeSolverHTTP01Ingress{},
+					},
+				},
+			},
+		},
+	})
+}
+
+func newClusterIssuer(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, labels map[string]string, spec IssuerSpec) *ClusterIssuer {
+
+	issuer := &ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: spec,
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, issuer, scheme)
+
+	return issuer
+}
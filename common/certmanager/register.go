@@ -11,6 +11,9 @@ const GroupName = "operator.cert-manager.io"
 // SchemeGroupVersion is group version used to register these objects
 var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1alpha1"}
 
+// CertificatesGroupVersion is group version used to register the issuers and certificates
+var CertificatesGroupVersion = schema.GroupVersion{Group: "cert-manager.io", Version: "v1"}
+
 var (
 	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
 	AddToScheme   = SchemeBuilder.AddToScheme
@@ -23,5 +26,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&CertManagerList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	scheme.AddKnownTypes(CertificatesGroupVersion,
+		&ClusterIssuer{},
+		&ClusterIssuerList{},
+		&Certificate{},
+		&CertificateList{},
+	)
+	metav1.AddToGroupVersion(scheme, CertificatesGroupVersion)
 	return nil
 }
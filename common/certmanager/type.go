@@ -19,3 +19,82 @@ type CertManagerList struct {
 
 	Items []CertManager `json:"items"`
 }
+
+type ClusterIssuer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IssuerSpec `json:"spec,omitempty"`
+}
+
+type IssuerSpec struct {
+	SelfSigned *SelfSignedIssuer `json:"selfSigned,omitempty"`
+	CA         *CAIssuer         `json:"ca,omitempty"`
+	ACME       *ACMEIssuer       `json:"acme,omitempty"`
+}
+
+type SelfSignedIssuer struct{}
+
+type CAIssuer struct {
+	SecretName string `json:"secretName"`
+}
+
+type ACMEIssuer struct {
+	Email               string                `json:"email,omitempty"`
+	Server              string                `json:"server"`
+	SkipTLSVerify       bool                  `json:"skipTLSVerify,omitempty"`
+	PrivateKeySecretRef SecretKeySelector     `json:"privateKeySecretRef"`
+	Solvers             []ACMEChallengeSolver `json:"solvers,omitempty"`
+}
+
+type SecretKeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key,omitempty"`
+}
+
+type ACMEChallengeSolver struct {
+	HTTP01 *ACMEChallengeSolverHTTP01 `json:"http01,omitempty"`
+}
+
+type ACMEChallengeSolverHTTP01 struct {
+	Ingress *ACMEChallengeSolverHTTP01Ingress `json:"ingress,omitempty"`
+}
+
+type ACMEChallengeSolverHTTP01Ingress struct {
+	Class *string `json:"class,omitempty"`
+}
+
+type ClusterIssuerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ClusterIssuer `json:"items"`
+}
+
+type Certificate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CertificateSpec `json:"spec,omitempty"`
+}
+
+type CertificateSpec struct {
+	CommonName string          `json:"commonName,omitempty"`
+	DNSNames   []string        `json:"dnsNames,omitempty"`
+	SecretName string          `json:"secretName"`
+	IsCA       bool            `json:"isCA,omitempty"`
+	IssuerRef  ObjectReference `json:"issuerRef"`
+}
+
+type ObjectReference struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind,omitempty"`
+	Group string `json:"group,omitempty"`
+}
+
+type CertificateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Certificate `json:"items"`
+}
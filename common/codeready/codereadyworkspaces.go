@@ -50,7 +50,7 @@ func NewCustomResource(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 				CheImageTag: "",
 				CheFlavor:   "codeready",
 				CustomCheProperties: map[string]string{
-					"CHE_INFRA_KUBERNETES_NAMESPACE_DEFAULT": "<username>-workspace",
+					"CHE_INFRA_KUBERNETES_NAMESPACE_DEFAULT": WorkspaceNamespaceName(workshop, "<username>"),
 					"CHE_LIMITS_USER_WORKSPACES_RUN_COUNT":   "2",
 					"CHE_LIMITS_WORKSPACE_IDLE_TIMEOUT":      "0",
 				},
@@ -0,0 +1,66 @@
+package codeready
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// WorkspaceNamespaceName returns the name of the workspace namespace of a user,
+// the CheCluster is configured with the one of the "<username>" placeholder
+func WorkspaceNamespaceName(workshop *workshopv1.Workshop, username string) string {
+	return kubernetes.PrefixedName(workshop, username+"-workspace")
+}
+
+// NewWorkspaceNamespace creates a workspace namespace provisioned in advance for a user
+func NewWorkspaceNamespace(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, username string) *corev1.Namespace {
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"app.kubernetes.io/part-of":   "che.eclipse.org",
+				"app.kubernetes.io/component": "workspaces-namespace",
+			},
+			Annotations: map[string]string{
+				"che.eclipse.org/username": username,
+			},
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, namespace, scheme)
+
+	return namespace
+}
+
+// NewWorkspaceSecret creates a Secret mounted as files into every workspace of the namespace
+func NewWorkspaceSecret(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, mountPath string, stringData map[string]string) *corev1.Secret {
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/part-of":   "che.eclipse.org",
+				"app.kubernetes.io/component": "workspace-secret",
+			},
+			Annotations: map[string]string{
+				"che.eclipse.org/automount-workspace-secret": "true",
+				"che.eclipse.org/mount-as":                   "file",
+				"che.eclipse.org/mount-path":                 mountPath,
+			},
+		},
+		StringData: stringData,
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, secret, scheme)
+
+	return secret
+}
@@ -0,0 +1,44 @@
+package gitea
+
+import "fmt"
+
+// NewConfiguration creates the content of the Gitea app.ini file
+func NewConfiguration(host string, secretKey string, internalToken string, jwtSecret string) string {
+	return fmt.Sprintf(`APP_NAME = Gitea
+RUN_MODE = prod
+
+[repository]
+ROOT = /var/lib/gitea/git/repositories
+
+[server]
+PROTOCOL         = http
+HTTP_PORT        = 3000
+DOMAIN           = %[1]s
+ROOT_URL         = https://%[1]s/
+APP_DATA_PATH    = /var/lib/gitea/data
+DISABLE_SSH      = true
+START_SSH_SERVER = false
+LFS_START_SERVER = false
+OFFLINE_MODE     = true
+
+[database]
+DB_TYPE = sqlite3
+PATH    = /var/lib/gitea/data/gitea.db
+
+[security]
+INSTALL_LOCK   = true
+SECRET_KEY     = %[2]s
+INTERNAL_TOKEN = %[3]s
+
+[oauth2]
+JWT_SECRET = %[4]s
+
+[service]
+DISABLE_REGISTRATION = false
+REQUIRE_SIGNIN_VIEW  = false
+
+[log]
+MODE  = console
+LEVEL = info
+`, host, secretKey, internalToken, jwtSecret)
+}
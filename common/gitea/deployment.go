@@ -0,0 +1,202 @@
+package gitea
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// NewDeployment creates a Gitea Deployment
+func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, adminSecretName string) *appsv1.Deployment {
+
+	image := workshop.Spec.Infrastructure.Gitea.Image.Name + ":" + workshop.Spec.Infrastructure.Gitea.Image.Tag
+	if image == ":" {
+		image = "docker.io/gitea/gitea:1.14.2-rootless"
+	}
+
+	replicas := int32(1)
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "GITEA_WORK_DIR",
+			Value: "/var/lib/gitea",
+		},
+		{
+			Name:  "GITEA_CUSTOM",
+			Value: "/var/lib/gitea/custom",
+		},
+		{
+			Name:  "GITEA_APP_INI",
+			Value: "/etc/gitea/app.ini",
+		},
+		{
+			Name:  "HOME",
+			Value: "/var/lib/gitea/git",
+		},
+	}
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      name + "-data",
+			MountPath: "/var/lib/gitea",
+		},
+		{
+			Name:      name + "-config",
+			MountPath: "/etc/gitea",
+			ReadOnly:  true,
+		},
+	}
+
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{
+						{
+							Name: name + "-data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: name,
+								},
+							},
+						},
+						{
+							Name: name + "-config",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: name + "-config",
+								},
+							},
+						},
+					},
+					InitContainers: []corev1.Container{
+						{
+							Name:            "init",
+							Image:           image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command: []string{
+								"/bin/sh",
+								"-ec",
+							},
+							Args: []string{
+								`mkdir -p ${HOME} ${GITEA_CUSTOM}
+gitea migrate
+gitea admin user list | grep -qw "${ADMIN_USERNAME}" || \
+gitea admin user create --admin --username "${ADMIN_USERNAME}" --password "${ADMIN_PASSWORD}" \
+  --email "${ADMIN_USERNAME}@none.com" --must-change-password=false`,
+							},
+							Env: append(env,
+								corev1.EnvVar{
+									Name: "ADMIN_USERNAME",
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: &corev1.SecretKeySelector{
+											Key: "username",
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: adminSecretName,
+											},
+										},
+									},
+								},
+								corev1.EnvVar{
+									Name: "ADMIN_PASSWORD",
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: &corev1.SecretKeySelector{
+											Key: "password",
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: adminSecretName,
+											},
+										},
+									},
+								},
+							),
+							VolumeMounts: volumeMounts,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            name,
+							Image:           image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command: []string{
+								"/usr/local/bin/gitea",
+								"web",
+							},
+							Env: env,
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("200m"),
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("1"),
+									corev1.ResourceMemory: resource.MustParse("1Gi"),
+								},
+							},
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: 3000,
+									Protocol:      "TCP",
+								},
+							},
+							ReadinessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: "/api/v1/version",
+										Port: intstr.IntOrString{
+											Type:   intstr.Int,
+											IntVal: int32(3000),
+										},
+									},
+								},
+								InitialDelaySeconds: 5,
+								PeriodSeconds:       10,
+								FailureThreshold:    10,
+								TimeoutSeconds:      1,
+							},
+							LivenessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									TCPSocket: &corev1.TCPSocketAction{
+										Port: intstr.IntOrString{
+											Type:   intstr.Int,
+											IntVal: int32(3000),
+										},
+									},
+								},
+								InitialDelaySeconds: 30,
+								PeriodSeconds:       10,
+								FailureThreshold:    3,
+								TimeoutSeconds:      1,
+							},
+							VolumeMounts: volumeMounts,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, dep, scheme)
+
+	return dep
+}
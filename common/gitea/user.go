@@ -0,0 +1,20 @@
+package gitea
+
+type giteaUser struct {
+	Username           string `json:"username"`
+	Email              string `json:"email"`
+	Password           string `json:"password"`
+	MustChangePassword bool   `json:"must_change_password"`
+	SendNotify         bool   `json:"send_notify"`
+}
+
+// NewUser creates the payload of a Gitea user
+func NewUser(username string, password string) *giteaUser {
+	return &giteaUser{
+		Username:           username,
+		Email:              username + "@none.com",
+		Password:           password,
+		MustChangePassword: false,
+		SendNotify:         false,
+	}
+}
@@ -0,0 +1,71 @@
+package kubernetes
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// IngressGVK is the networking.k8s.io/v1 Ingress, built as an unstructured object
+// because the vendored Kubernetes API only provides the deprecated v1beta1 one
+var IngressGVK = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+
+// NewIngress creates an Ingress, the Kubernetes counterpart of a Route.
+// An empty ingressClassName uses the default IngressClass of the cluster.
+func NewIngress(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, serviceName string, port int32, host string, secured bool,
+	ingressClassName string) *unstructured.Unstructured {
+
+	spec := map[string]interface{}{
+		"rules": []interface{}{
+			map[string]interface{}{
+				"host": host,
+				"http": map[string]interface{}{
+					"paths": []interface{}{
+						map[string]interface{}{
+							"path":     "/",
+							"pathType": "Prefix",
+							"backend": map[string]interface{}{
+								"service": map[string]interface{}{
+									"name": serviceName,
+									"port": map[string]interface{}{
+										"number": int64(port),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if ingressClassName != "" {
+		spec["ingressClassName"] = ingressClassName
+	}
+
+	if secured {
+		spec["tls"] = []interface{}{
+			map[string]interface{}{
+				"hosts": []interface{}{host},
+			},
+		}
+	}
+
+	ingress := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	ingress.SetGroupVersionKind(IngressGVK)
+	ingress.SetName(name)
+	ingress.SetNamespace(namespace)
+	ingress.SetLabels(newRouteLabels(workshop, labels))
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, ingress, scheme)
+
+	return ingress
+}
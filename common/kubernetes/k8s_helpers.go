@@ -32,6 +32,7 @@ func GetK8Client() *k8s {
 	cfg, err := config.GetConfig()
 	if err != nil {
 		log.Errorf(err.Error())
+		return nil
 	}
 	client := k8s{}
 	client.clientset, err = kubernetes.NewForConfig(cfg)
@@ -0,0 +1,35 @@
+package kubernetes
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// WorkshopLabel identifies the resources created for a Workshop
+// which cannot be owned by it, like cluster-scoped resources.
+// Its value is given by WorkshopLabelValue.
+const WorkshopLabel = "workshop.mcouliba.com/workshop"
+
+// WorkshopsAnnotation lists the Workshops using a resource shared across the cluster,
+// like an operator Subscription, so that it is deleted with the last one
+const WorkshopsAnnotation = "workshop.mcouliba.com/workshops"
+
+// ReplicasAnnotation records the replicas of a workload scaled down by the operator
+const ReplicasAnnotation = "workshop.mcouliba.com/replicas"
+
+// PasswordHashAnnotation records the SHA-256 of the password set in a resource,
+// so that a change is detected without hashing it again with bcrypt
+const PasswordHashAnnotation = "workshop.mcouliba.com/password-hash"
+
+// WorkshopLabelValue returns the value of WorkshopLabel for a Workshop, its namespace and name
+// joined with a dot, which cannot appear in a namespace, or their hash if it is too long for a label
+func WorkshopLabelValue(workshop *workshopv1.Workshop) string {
+	value := workshop.Namespace + "." + workshop.Name
+	if len(value) > validation.LabelValueMaxLength {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(value)))[:validation.LabelValueMaxLength]
+	}
+	return value
+}
@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"bytes"
+	"regexp"
+	"text/template"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// documentSeparator splits a YAML stream into documents
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// NewUnstructuredFromTemplate renders a YAML manifest Go template with data
+// and creates the resulting object in the namespace
+func NewUnstructuredFromTemplate(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	manifest string, namespace string, labels map[string]string, data interface{}) (*unstructured.Unstructured, error) {
+
+	rendered, err := renderManifest(manifest, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return newUnstructured(workshop, scheme, rendered, namespace, labels)
+}
+
+// NewUnstructuredListFromTemplate renders a YAML stream Go template with data
+// and creates the resulting objects in the namespace, skipping the empty documents
+func NewUnstructuredListFromTemplate(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	manifest string, namespace string, labels map[string]string, data interface{}) ([]*unstructured.Unstructured, error) {
+
+	rendered, err := renderManifest(manifest, data)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := []*unstructured.Unstructured{}
+	for _, document := range documentSeparator.Split(string(rendered), -1) {
+		obj, err := newUnstructured(workshop, scheme, []byte(document), namespace, labels)
+		if err != nil {
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+func renderManifest(manifest string, data interface{}) ([]byte, error) {
+	tmpl, err := template.New("manifest").Option("missingkey=error").Parse(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return nil, err
+	}
+
+	return rendered.Bytes(), nil
+}
+
+func newUnstructured(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	document []byte, namespace string, labels map[string]string) (*unstructured.Unstructured, error) {
+
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(document, &obj.Object); err != nil {
+		return nil, err
+	}
+	if len(obj.Object) == 0 {
+		return obj, nil
+	}
+
+	obj.SetNamespace(namespace)
+
+	objLabels := obj.GetLabels()
+	if objLabels == nil {
+		objLabels = map[string]string{}
+	}
+	for key, value := range labels {
+		objLabels[key] = value
+	}
+	obj.SetLabels(objLabels)
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, obj, scheme)
+
+	return obj, nil
+}
@@ -0,0 +1,14 @@
+package kubernetes
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+)
+
+// PrefixedName returns the name of a namespace or cluster-scoped resource of the Workshop,
+// prefixed so that it does not collide with the ones of other Workshops
+func PrefixedName(workshop *workshopv1.Workshop, name string) string {
+	if workshop.Spec.Prefix == "" {
+		return name
+	}
+	return workshop.Spec.Prefix + "-" + name
+}
@@ -0,0 +1,46 @@
+package kubernetes
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// NamespaceNameLabel is set by Kubernetes on every namespace from 1.21 (OpenShift 4.8)
+const NamespaceNameLabel = "kubernetes.io/metadata.name"
+
+// NewNetworkPolicy creates a Network Policy selecting all the pods of the namespace.
+// Without ingress rules, it denies all the incoming traffic.
+func NewNetworkPolicy(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string,
+	ingress []networkingv1.NetworkPolicyIngressRule) *networkingv1.NetworkPolicy {
+
+	networkPolicy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			Ingress:     ingress,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, networkPolicy, scheme)
+
+	return networkPolicy
+}
+
+// NewNamespacePeer selects all the pods of the namespaces matching the labels
+func NewNamespacePeer(labels map[string]string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: labels,
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package kubernetes
+
+import (
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// Platform is the flavour of the cluster the operator runs on
+type Platform string
+
+const (
+	// OpenShift clusters serve Routes, SCCs and the Red Hat catalogs
+	OpenShift Platform = "OpenShift"
+	// Kubernetes clusters use Ingresses, Pod Security admission and the OperatorHub.io catalog
+	Kubernetes Platform = "Kubernetes"
+)
+
+// DetectPlatform returns OpenShift when the cluster serves the Route API, Kubernetes otherwise
+func DetectPlatform(config *rest.Config) (Platform, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	groups, err := discoveryClient.ServerGroups()
+	if err != nil {
+		return "", err
+	}
+
+	for _, group := range groups.Groups {
+		if group.Name == routev1.GroupName {
+			return OpenShift, nil
+		}
+	}
+	return Kubernetes, nil
+}
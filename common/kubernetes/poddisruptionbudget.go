@@ -0,0 +1,34 @@
+package kubernetes
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// NewPodDisruptionBudget creates a Pod Disruption Budget
+func NewPodDisruptionBudget(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, maxUnavailable int32) *policyv1beta1.PodDisruptionBudget {
+
+	maxUnavailableIntStr := intstr.FromInt(int(maxUnavailable))
+
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailableIntStr,
+			Selector:       &metav1.LabelSelector{MatchLabels: labels},
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, pdb, scheme)
+
+	return pdb
+}
@@ -4,125 +4,6 @@ import (
 	rbac "k8s.io/api/rbac/v1"
 )
 
-//GiteaRules gets Rules
-func GiteaRules() []rbac.PolicyRule {
-	return []rbac.PolicyRule{
-		{
-			APIGroups: []string{
-				"",
-			},
-			Resources: []string{
-				"pods",
-				"services",
-				"endpoints",
-				"persistentvolumeclaims",
-				"events",
-				"configmaps",
-				"secrets",
-				"serviceaccounts",
-			},
-			Verbs: []string{
-				"create",
-				"update",
-				"delete",
-				"get",
-				"list",
-				"watch",
-				"patch",
-			},
-		},
-		{
-			APIGroups: []string{
-				"apps",
-			},
-			Resources: []string{
-				"deployments",
-			},
-			Verbs: []string{
-				"create",
-				"update",
-				"delete",
-				"get",
-				"list",
-				"watch",
-				"patch",
-			},
-		},
-		{
-			APIGroups: []string{
-				"apps",
-			},
-			Resources: []string{
-				"replicasets",
-			},
-			Verbs: []string{
-				"get",
-			},
-		},
-		{
-			APIGroups: []string{
-				"route.openshift.io",
-			},
-			Resources: []string{
-				"routes",
-				"routes/custom-host",
-			},
-			Verbs: []string{
-				"create",
-				"update",
-				"delete",
-				"get",
-				"list",
-				"watch",
-				"patch",
-			},
-		},
-		{
-			APIGroups: []string{
-				"monitoring.coreos.com",
-			},
-			Resources: []string{
-				"servicemonitors",
-			},
-			Verbs: []string{
-				"get",
-				"create",
-			},
-		},
-		{
-			APIGroups: []string{
-				"gpte.opentlc.com",
-			},
-			Resources: []string{
-				"*",
-			},
-			Verbs: []string{
-				"create",
-				"update",
-				"delete",
-				"get",
-				"list",
-				"watch",
-				"patch",
-			},
-		},
-		{
-			APIGroups: []string{
-				"apps",
-			},
-			ResourceNames: []string{
-				"gitea-operator",
-			},
-			Resources: []string{
-				"deployments/finalizers",
-			},
-			Verbs: []string{
-				"update",
-			},
-		},
-	}
-}
-
 //JaegerUserRules gets Rules
 func JaegerUserRules() []rbac.PolicyRule {
 	return []rbac.PolicyRule{
@@ -318,3 +199,23 @@ func ArgoCDRules() []rbac.PolicyRule {
 		},
 	}
 }
+
+//SecurityContextConstraintsUseRules gets Rules
+func SecurityContextConstraintsUseRules(name string) []rbac.PolicyRule {
+	return []rbac.PolicyRule{
+		{
+			APIGroups: []string{
+				"security.openshift.io",
+			},
+			Resources: []string{
+				"securitycontextconstraints",
+			},
+			ResourceNames: []string{
+				name,
+			},
+			Verbs: []string{
+				"use",
+			},
+		},
+	}
+}
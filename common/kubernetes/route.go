@@ -22,7 +22,7 @@ func NewRoute(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    newRouteLabels(workshop, labels),
 		},
 		Spec: routev1.RouteSpec{
 			To: routev1.RouteTargetReference{
@@ -54,7 +54,7 @@ func NewSecuredRoute(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    newRouteLabels(workshop, labels),
 		},
 		Spec: routev1.RouteSpec{
 			To: routev1.RouteTargetReference{
@@ -75,3 +75,13 @@ func NewSecuredRoute(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 
 	return route
 }
+
+func newRouteLabels(workshop *workshopv1.Workshop, labels map[string]string) map[string]string {
+	routeLabels := map[string]string{
+		WorkshopLabel: WorkshopLabelValue(workshop),
+	}
+	for key, value := range labels {
+		routeLabels[key] = value
+	}
+	return routeLabels
+}
@@ -46,3 +46,26 @@ func NewCrtSecret(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 
 	return secret
 }
+
+// NewTLSSecret create a TLS Secret
+func NewTLSSecret(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, caCrt []byte, crt []byte, key []byte) *corev1.Secret {
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"ca.crt":  caCrt,
+			"tls.crt": crt,
+			"tls.key": key,
+		},
+	}
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, secret, scheme)
+
+	return secret
+}
@@ -0,0 +1,62 @@
+package kubernetes
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	securityv1 "github.com/openshift/api/security/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// NewSecurityContextConstraints creates a SecurityContextConstraints derived from restricted,
+// relaxing only the user and the capabilities
+func NewSecurityContextConstraints(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, labels map[string]string, runAsUser securityv1.RunAsUserStrategyType,
+	allowedCapabilities []corev1.Capability, allowPrivilegeEscalation bool) *securityv1.SecurityContextConstraints {
+
+	scc := &securityv1.SecurityContextConstraints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		AllowPrivilegedContainer: false,
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		AllowedCapabilities:      allowedCapabilities,
+		RequiredDropCapabilities: []corev1.Capability{
+			"KILL",
+			"MKNOD",
+		},
+		AllowHostDirVolumePlugin: false,
+		AllowHostIPC:             false,
+		AllowHostNetwork:         false,
+		AllowHostPID:             false,
+		AllowHostPorts:           false,
+		ReadOnlyRootFilesystem:   false,
+		RunAsUser: securityv1.RunAsUserStrategyOptions{
+			Type: runAsUser,
+		},
+		SELinuxContext: securityv1.SELinuxContextStrategyOptions{
+			Type: securityv1.SELinuxStrategyMustRunAs,
+		},
+		FSGroup: securityv1.FSGroupStrategyOptions{
+			Type: securityv1.FSGroupStrategyRunAsAny,
+		},
+		SupplementalGroups: securityv1.SupplementalGroupsStrategyOptions{
+			Type: securityv1.SupplementalGroupsStrategyRunAsAny,
+		},
+		Volumes: []securityv1.FSType{
+			securityv1.FSTypeConfigMap,
+			securityv1.FSTypeDownwardAPI,
+			securityv1.FSTypeEmptyDir,
+			securityv1.FSTypePersistentVolumeClaim,
+			securityv1.FSProjected,
+			securityv1.FSTypeSecret,
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, scc, scheme)
+
+	return scc
+}
@@ -104,3 +104,14 @@ func NewServiceWithTarget(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 
 	return service
 }
+
+// NewHeadlessService creates a headless service publishing the addresses of pods that are not ready
+func NewHeadlessService(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, portName []string, portNumber []int32) *corev1.Service {
+
+	service := NewService(workshop, scheme, name, namespace, labels, portName, portNumber)
+	service.Spec.ClusterIP = corev1.ClusterIPNone
+	service.Spec.PublishNotReadyAddresses = true
+
+	return service
+}
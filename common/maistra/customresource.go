@@ -13,7 +13,22 @@ import (
 func NewServiceMeshControlPlaneCR(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	name string, namespace string) *maistrav2.ServiceMeshControlPlane {
 
+	controlPlane := workshop.Spec.Infrastructure.ServiceMesh.ControlPlane
+
+	version := controlPlane.Version
+	if version == "" {
+		version = "v2.0"
+	}
+
 	var sampling int32 = 10000
+	if controlPlane.TracingSampling != nil {
+		sampling = *controlPlane.TracingSampling
+	}
+
+	ingressReplicas := controlPlane.Gateways.IngressReplicas
+	if ingressReplicas < 1 {
+		ingressReplicas = 1
+	}
 
 	smcp := &maistrav2.ServiceMeshControlPlane{
 		ObjectMeta: metav1.ObjectMeta{
@@ -21,7 +36,7 @@ func NewServiceMeshControlPlaneCR(workshop *workshopv1.Workshop, scheme *runtime
 			Namespace: namespace,
 		},
 		Spec: maistrav2.ControlPlaneSpec{
-			Version: "v2.0",
+			Version: version,
 			Tracing: &maistrav2.TracingConfig{
 				Type:     maistrav2.TracerTypeJaeger,
 				Sampling: &sampling,
@@ -32,16 +47,42 @@ func NewServiceMeshControlPlaneCR(workshop *workshopv1.Workshop, scheme *runtime
 			Telemetry: &maistrav2.TelemetryConfig{
 				Type: maistrav2.TelemetryTypeIstiod,
 			},
+			Gateways: &maistrav2.GatewaysConfig{
+				ClusterIngress: &maistrav2.ClusterIngressGatewayConfig{
+					IngressGatewayConfig: maistrav2.IngressGatewayConfig{
+						GatewayConfig: maistrav2.GatewayConfig{
+							Runtime: &maistrav2.ComponentRuntimeConfig{
+								Deployment: &maistrav2.DeploymentRuntimeConfig{
+									Replicas: &ingressReplicas,
+								},
+							},
+						},
+					},
+				},
+				ClusterEgress: &maistrav2.EgressGatewayConfig{
+					GatewayConfig: maistrav2.GatewayConfig{
+						Enablement: newEnablement(controlPlane.Gateways.Egress),
+					},
+				},
+				OpenShiftRoute: &maistrav2.OpenShiftRouteConfig{
+					Enablement: newEnablement(controlPlane.Gateways.OpenShiftRoute),
+				},
+			},
 			Addons: &maistrav2.AddonsConfig{
 				Jaeger: &maistrav2.JaegerAddonConfig{
 					Install: &maistrav2.JaegerInstallConfig{
-						Storage: &maistrav2.JaegerStorageConfig{
-							Type: maistrav2.JaegerStorageTypeMemory,
-						},
+						Storage: newJaegerStorageConfig(controlPlane.Jaeger),
 					},
 				},
-				Prometheus: &maistrav2.PrometheusAddonConfig{},
-				Kiali:      &maistrav2.KialiAddonConfig{},
+				Grafana: &maistrav2.GrafanaAddonConfig{
+					Enablement: newEnablement(controlPlane.Addons.Grafana),
+				},
+				Prometheus: &maistrav2.PrometheusAddonConfig{
+					Enablement: newEnablement(controlPlane.Addons.Prometheus),
+				},
+				Kiali: &maistrav2.KialiAddonConfig{
+					Enablement: newEnablement(controlPlane.Addons.Kiali),
+				},
 			},
 		},
 	}
@@ -52,6 +93,105 @@ func NewServiceMeshControlPlaneCR(workshop *workshopv1.Workshop, scheme *runtime
 	return smcp
 }
 
+// NewUserServiceMeshControlPlaneCR create a lightweight SMCP Custom Resource dedicated to a user
+func NewUserServiceMeshControlPlaneCR(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string) *maistrav2.ServiceMeshControlPlane {
+
+	smcp := NewServiceMeshControlPlaneCR(workshop, scheme, name, namespace)
+
+	disabled := false
+	ingressReplicas := int32(1)
+
+	smcp.Spec.Gateways.ClusterIngress.Runtime.Deployment.Replicas = &ingressReplicas
+	smcp.Spec.Gateways.ClusterEgress.Enabled = &disabled
+	smcp.Spec.Addons.Grafana.Enabled = &disabled
+	smcp.Spec.Addons.Jaeger.Install.Storage = &maistrav2.JaegerStorageConfig{
+		Type: maistrav2.JaegerStorageTypeMemory,
+	}
+
+	return smcp
+}
+
+// newEnablement enables a feature unless it is explicitly disabled
+func newEnablement(enabled *bool) maistrav2.Enablement {
+	value := true
+	if enabled != nil {
+		value = *enabled
+	}
+	return maistrav2.Enablement{
+		Enabled: &value,
+	}
+}
+
+func newJaegerStorageConfig(jaeger workshopv1.JaegerSpec) *maistrav2.JaegerStorageConfig {
+	if jaeger.StorageType != string(maistrav2.JaegerStorageTypeElasticsearch) {
+		return &maistrav2.JaegerStorageConfig{
+			Type: maistrav2.JaegerStorageTypeMemory,
+		}
+	}
+
+	nodeCount := jaeger.ElasticsearchNodeCount
+	if nodeCount < 1 {
+		nodeCount = 1
+	}
+
+	storageSize := jaeger.ElasticsearchStorageSize
+	if storageSize == "" {
+		storageSize = "10Gi"
+	}
+
+	retentionDays := jaeger.ElasticsearchRetentionDays
+	if retentionDays < 1 {
+		retentionDays = 7
+	}
+
+	// A single node cannot hold replica shards
+	redundancyPolicy := "SingleRedundancy"
+	if nodeCount == 1 {
+		redundancyPolicy = "ZeroRedundancy"
+	}
+
+	return &maistrav2.JaegerStorageConfig{
+		Type: maistrav2.JaegerStorageTypeElasticsearch,
+		Elasticsearch: &maistrav2.JaegerElasticsearchStorageConfig{
+			NodeCount: &nodeCount,
+			Storage: maistrav1.NewHelmValues(map[string]interface{}{
+				"size": storageSize,
+			}),
+			RedundancyPolicy: redundancyPolicy,
+			IndexCleaner: maistrav1.NewHelmValues(map[string]interface{}{
+				"enabled":      true,
+				"numberOfDays": int64(retentionDays),
+				"schedule":     "55 23 * * *",
+			}),
+		},
+	}
+}
+
+// NewServiceMeshMemberCR create a SMM Custom Resource
+func NewServiceMeshMemberCR(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string,
+	controlPlaneName string, controlPlaneNamespace string) *maistrav1.ServiceMeshMember {
+	smm := &maistrav1.ServiceMeshMember{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: maistrav1.ServiceMeshMemberSpec{
+			ControlPlaneRef: maistrav1.ServiceMeshControlPlaneRef{
+				Name:      controlPlaneName,
+				Namespace: controlPlaneNamespace,
+			},
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, smm, scheme)
+
+	return smm
+}
+
 // NewServiceMeshMemberRollCR create a SMMR Custom Resource
 func NewServiceMeshMemberRollCR(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	name string, namespace string, members []string) *maistrav1.ServiceMeshMemberRoll {
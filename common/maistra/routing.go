@@ -0,0 +1,26 @@
+package maistra
+
+// RoutingTemplateData holds the values available in the user routing templates
+type RoutingTemplateData struct {
+	Username           string
+	Project            string
+	Host               string
+	AppsHostnameSuffix string
+}
+
+// DefaultGatewayTemplate exposes the user host through the Istio ingress gateway
+const DefaultGatewayTemplate = `apiVersion: networking.istio.io/v1alpha3
+kind: Gateway
+metadata:
+  name: {{ .Project }}-gateway
+spec:
+  selector:
+    istio: ingressgateway
+  servers:
+  - port:
+      number: 80
+      name: http
+      protocol: HTTP
+    hosts:
+    - "{{ .Host }}"
+`
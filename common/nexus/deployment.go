@@ -0,0 +1,133 @@
+package nexus
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// NewDeployment creates a Nexus Deployment
+func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string) *appsv1.Deployment {
+
+	image := workshop.Spec.Infrastructure.Nexus.Image.Name + ":" + workshop.Spec.Infrastructure.Nexus.Image.Tag
+	if image == ":" {
+		image = "docker.io/sonatype/nexus3:3.30.1"
+	}
+
+	replicas := int32(1)
+
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{
+						{
+							Name: name + "-data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: name,
+								},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            name,
+							Image:           image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Env: []corev1.EnvVar{
+								{
+									Name: "INSTALL4J_ADD_VM_PARAMS",
+									Value: "-Xms1024m -Xmx1024m -XX:MaxDirectMemorySize=1024m " +
+										"-Djava.util.prefs.userRoot=/nexus-data/javaprefs " +
+										"-Dnexus.security.randompassword=false",
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("1"),
+									corev1.ResourceMemory: resource.MustParse("2Gi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("2"),
+									corev1.ResourceMemory: resource.MustParse("3Gi"),
+								},
+							},
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: 8081,
+									Protocol:      "TCP",
+								},
+								{
+									Name:          "docker",
+									ContainerPort: 5000,
+									Protocol:      "TCP",
+								},
+							},
+							ReadinessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: "/service/rest/v1/status",
+										Port: intstr.IntOrString{
+											Type:   intstr.Int,
+											IntVal: int32(8081),
+										},
+									},
+								},
+								InitialDelaySeconds: 60,
+								PeriodSeconds:       10,
+								FailureThreshold:    30,
+								TimeoutSeconds:      5,
+							},
+							LivenessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									TCPSocket: &corev1.TCPSocketAction{
+										Port: intstr.IntOrString{
+											Type:   intstr.Int,
+											IntVal: int32(8081),
+										},
+									},
+								},
+								InitialDelaySeconds: 240,
+								PeriodSeconds:       10,
+								FailureThreshold:    6,
+								TimeoutSeconds:      5,
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      name + "-data",
+									MountPath: "/nexus-data",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	ctrl.SetControllerReference(workshop, dep, scheme)
+
+	return dep
+}
@@ -0,0 +1,166 @@
+package nexus
+
+// Repository is the payload of the Nexus Repository Manager REST API
+// used to create a repository
+type Repository struct {
+	Format        string            `json:"-"`
+	Type          string            `json:"-"`
+	Name          string            `json:"name"`
+	Online        bool              `json:"online"`
+	Storage       repositoryStorage `json:"storage"`
+	Proxy         *repositoryProxy  `json:"proxy,omitempty"`
+	NegativeCache *repositoryCache  `json:"negativeCache,omitempty"`
+	HTTPClient    *repositoryClient `json:"httpClient,omitempty"`
+	Group         *repositoryGroup  `json:"group,omitempty"`
+	Maven         *repositoryMaven  `json:"maven,omitempty"`
+	Docker        *repositoryDocker `json:"docker,omitempty"`
+}
+
+type repositoryStorage struct {
+	BlobStoreName               string `json:"blobStoreName"`
+	StrictContentTypeValidation bool   `json:"strictContentTypeValidation"`
+	WritePolicy                 string `json:"writePolicy,omitempty"`
+}
+
+type repositoryProxy struct {
+	RemoteURL      string `json:"remoteUrl"`
+	ContentMaxAge  int    `json:"contentMaxAge"`
+	MetadataMaxAge int    `json:"metadataMaxAge"`
+}
+
+type repositoryCache struct {
+	Enabled    bool `json:"enabled"`
+	TimeToLive int  `json:"timeToLive"`
+}
+
+type repositoryClient struct {
+	Blocked   bool `json:"blocked"`
+	AutoBlock bool `json:"autoBlock"`
+}
+
+type repositoryGroup struct {
+	MemberNames []string `json:"memberNames"`
+}
+
+type repositoryMaven struct {
+	VersionPolicy string `json:"versionPolicy"`
+	LayoutPolicy  string `json:"layoutPolicy"`
+}
+
+type repositoryDocker struct {
+	V1Enabled      bool  `json:"v1Enabled"`
+	ForceBasicAuth bool  `json:"forceBasicAuth"`
+	HTTPPort       int32 `json:"httpPort,omitempty"`
+}
+
+// NewRepositories returns the repositories to create in Nexus.
+// Groups are listed after their members so they can be created in order.
+func NewRepositories() []Repository {
+	return []Repository{
+		newMavenProxyRepository("maven-central", "https://repo1.maven.org/maven2/"),
+		newMavenProxyRepository("redhat-ga", "https://maven.repository.redhat.com/ga/"),
+		newMavenProxyRepository("jboss", "https://repository.jboss.org/nexus/content/groups/public"),
+		{
+			Format: "maven",
+			Type:   "hosted",
+			Name:   "releases",
+			Online: true,
+			Storage: repositoryStorage{
+				BlobStoreName:               "default",
+				StrictContentTypeValidation: true,
+				WritePolicy:                 "ALLOW_ONCE",
+			},
+			Maven: &repositoryMaven{
+				VersionPolicy: "RELEASE",
+				LayoutPolicy:  "PERMISSIVE",
+			},
+		},
+		newGroupRepository("maven", "maven-all-public", []string{"maven-central", "redhat-ga", "jboss"}),
+		{
+			Format: "docker",
+			Type:   "hosted",
+			Name:   "docker",
+			Online: true,
+			Storage: repositoryStorage{
+				BlobStoreName:               "default",
+				StrictContentTypeValidation: true,
+				WritePolicy:                 "ALLOW",
+			},
+			Docker: &repositoryDocker{
+				V1Enabled:      true,
+				ForceBasicAuth: true,
+				HTTPPort:       5000,
+			},
+		},
+		{
+			Format: "npm",
+			Type:   "proxy",
+			Name:   "npm",
+			Online: true,
+			Storage: repositoryStorage{
+				BlobStoreName:               "default",
+				StrictContentTypeValidation: true,
+			},
+			Proxy: &repositoryProxy{
+				RemoteURL:      "https://registry.npmjs.org",
+				ContentMaxAge:  1440,
+				MetadataMaxAge: 1440,
+			},
+			NegativeCache: &repositoryCache{
+				Enabled:    true,
+				TimeToLive: 1440,
+			},
+			HTTPClient: &repositoryClient{
+				Blocked:   false,
+				AutoBlock: true,
+			},
+		},
+		newGroupRepository("npm", "npm-all", []string{"npm"}),
+	}
+}
+
+func newMavenProxyRepository(name string, remoteURL string) Repository {
+	return Repository{
+		Format: "maven",
+		Type:   "proxy",
+		Name:   name,
+		Online: true,
+		Storage: repositoryStorage{
+			BlobStoreName:               "default",
+			StrictContentTypeValidation: true,
+		},
+		Proxy: &repositoryProxy{
+			RemoteURL:      remoteURL,
+			ContentMaxAge:  -1,
+			MetadataMaxAge: 1440,
+		},
+		NegativeCache: &repositoryCache{
+			Enabled:    true,
+			TimeToLive: 1440,
+		},
+		HTTPClient: &repositoryClient{
+			Blocked:   false,
+			AutoBlock: true,
+		},
+		Maven: &repositoryMaven{
+			VersionPolicy: "RELEASE",
+			LayoutPolicy:  "PERMISSIVE",
+		},
+	}
+}
+
+func newGroupRepository(format string, name string, members []string) Repository {
+	return Repository{
+		Format: format,
+		Type:   "group",
+		Name:   name,
+		Online: true,
+		Storage: repositoryStorage{
+			BlobStoreName:               "default",
+			StrictContentTypeValidation: true,
+		},
+		Group: &repositoryGroup{
+			MemberNames: members,
+		},
+	}
+}
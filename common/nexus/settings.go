@@ -0,0 +1,54 @@
+package nexus
+
+import (
+	"encoding/base64"
+)
+
+// ServiceURL returns the in-cluster URL of Nexus
+func ServiceURL(namespace string) string {
+	return "http://nexus." + namespace + ".svc:8081"
+}
+
+// MavenRepositoryURL returns the in-cluster URL of the Maven group repository
+func MavenRepositoryURL(namespace string) string {
+	return ServiceURL(namespace) + "/repository/maven-all-public/"
+}
+
+// NpmRepositoryURL returns the in-cluster URL of the npm group repository
+func NpmRepositoryURL(namespace string) string {
+	return ServiceURL(namespace) + "/repository/npm-all/"
+}
+
+// NewMavenSettings returns a settings.xml mirroring every repository to Nexus
+func NewMavenSettings(namespace string, username string, password string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<settings xmlns="http://maven.apache.org/SETTINGS/1.0.0"
+          xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+          xsi:schemaLocation="http://maven.apache.org/SETTINGS/1.0.0 http://maven.apache.org/xsd/settings-1.0.0.xsd">
+  <servers>
+    <server>
+      <id>nexus</id>
+      <username>` + username + `</username>
+      <password>` + password + `</password>
+    </server>
+  </servers>
+  <mirrors>
+    <mirror>
+      <id>nexus</id>
+      <mirrorOf>*</mirrorOf>
+      <url>` + MavenRepositoryURL(namespace) + `</url>
+    </mirror>
+  </mirrors>
+</settings>
+`
+}
+
+// NewNpmrc returns a .npmrc using Nexus as npm registry
+func NewNpmrc(namespace string, username string, password string) string {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+
+	return `registry=` + NpmRepositoryURL(namespace) + `
+always-auth=true
+_auth=` + auth + `
+`
+}
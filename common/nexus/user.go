@@ -0,0 +1,50 @@
+package nexus
+
+type nexusUser struct {
+	UserID       string   `json:"userId"`
+	FirstName    string   `json:"firstName"`
+	LastName     string   `json:"lastName"`
+	EmailAddress string   `json:"emailAddress"`
+	Password     string   `json:"password"`
+	Status       string   `json:"status"`
+	Roles        []string `json:"roles"`
+}
+
+type nexusRole struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Privileges  []string `json:"privileges"`
+	Roles       []string `json:"roles"`
+}
+
+// UserRoleID is the Nexus role granted to the workshop users
+const UserRoleID = "workshop-user"
+
+// NewUser creates a Nexus user with the workshop user role
+func NewUser(username string, password string) *nexusUser {
+	return &nexusUser{
+		UserID:       username,
+		FirstName:    username,
+		LastName:     username,
+		EmailAddress: username + "@none.com",
+		Password:     password,
+		Status:       "active",
+		Roles:        []string{UserRoleID},
+	}
+}
+
+// NewUserRole creates the Nexus role allowing users to read and deploy artifacts
+func NewUserRole() *nexusRole {
+	return &nexusRole{
+		ID:          UserRoleID,
+		Name:        UserRoleID,
+		Description: "Workshop user",
+		Privileges: []string{
+			"nx-repository-view-*-*-*",
+			"nx-search-read",
+			"nx-component-upload",
+		},
+		Roles: []string{},
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/nexus"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -19,10 +20,18 @@ func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	appsHostnameSuffix string, openshiftConsoleURL string) *appsv1.Deployment {
 
 	image := "quay.io/mcouliba/username-distribution:latest"
+	nexusNamespace := workshop.Status.Namespaces.Nexus
+	labDuration := "1week"
+	if schedule := workshop.Spec.Schedule; schedule.Start != nil && schedule.End != nil {
+		labDuration = fmt.Sprintf("%dh", int(schedule.End.Sub(schedule.Start.Time).Hours()))
+	}
 	labModuleURLs := "https://docs.openshift.com/container-platform/latest/welcome/index.html;openshift_docs"
 	guideURLParameters := "APPS_HOSTNAME_SUFFIX=" + appsHostnameSuffix +
 		"&USER_ID=%USER_ID%" +
 		"&OPENSHIFT_PASSWORD=" + workshop.Spec.User.Password +
+		"&NEXUS_URL=" + url.QueryEscape("https://nexus-"+nexusNamespace+"."+appsHostnameSuffix) +
+		"&NEXUS_MAVEN_URL=" + url.QueryEscape(nexus.MavenRepositoryURL(nexusNamespace)) +
+		"&NEXUS_NPM_URL=" + url.QueryEscape(nexus.NpmRepositoryURL(nexusNamespace)) +
 		"&WORKSHOP_GIT_REPO=" + url.QueryEscape(workshop.Spec.Source.GitURL) +
 		"&WORKSHOP_GIT_REF=" + workshop.Spec.Source.GitBranch
 
@@ -72,7 +81,7 @@ func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 								},
 								{
 									Name:  "LAB_DURATION_HOURS",
-									Value: "1week",
+									Value: labDuration,
 								},
 								{
 									Name:  "LAB_USER_COUNT",
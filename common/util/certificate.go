@@ -0,0 +1,117 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// Certificate holds a PEM encoded certificate and its private key
+type Certificate struct {
+	Cert []byte
+	Key  []byte
+}
+
+// NewCACertificate generates a self-signed CA certificate
+func NewCACertificate(commonName string, validity time.Duration) (*Certificate, error) {
+	template, err := newCertificateTemplate(commonName, validity)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	return signCertificate(template, template, key, key)
+}
+
+// NewServingCertificate generates a serving certificate for the given hosts signed by the CA
+func NewServingCertificate(ca *Certificate, hosts []string, validity time.Duration) (*Certificate, error) {
+	caCert, err := ParseCertificate(ca.Cert)
+	if err != nil {
+		return nil, err
+	}
+
+	caKeyBlock, _ := pem.Decode(ca.Key)
+	if caKeyBlock == nil {
+		return nil, errors.New("failed to decode the CA private key")
+	}
+	caKey, err := x509.ParsePKCS1PrivateKey(caKeyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := newCertificateTemplate(hosts[0], validity)
+	if err != nil {
+		return nil, err
+	}
+	template.DNSNames = hosts
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	return signCertificate(template, caCert, key, caKey)
+}
+
+// ParseCertificate decodes a PEM encoded certificate
+func ParseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("failed to decode the certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// IsCertificateExpiring tells whether a PEM encoded certificate is invalid or expires within the given duration
+func IsCertificateExpiring(certPEM []byte, within time.Duration) bool {
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		return true
+	}
+	return time.Now().Add(within).After(cert.NotAfter)
+}
+
+func newCertificateTemplate(commonName string, validity time.Duration) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: commonName,
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		BasicConstraintsValid: true,
+	}, nil
+}
+
+func signCertificate(template *x509.Certificate, parent *x509.Certificate,
+	key *rsa.PrivateKey, parentKey *rsa.PrivateKey) (*Certificate, error) {
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Certificate{
+		Cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:  pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+	}, nil
+}
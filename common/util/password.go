@@ -0,0 +1,32 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"math/big"
+)
+
+const passwordCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// RandomString returns a random alphanumeric string of the given length
+func RandomString(length int) string {
+	result := make([]byte, length)
+	max := big.NewInt(int64(len(passwordCharset)))
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		result[i] = passwordCharset[n.Int64()]
+	}
+	return string(result)
+}
+
+// RandomBase64 returns size random bytes encoded in unpadded URL-safe base64
+func RandomBase64(size int) string {
+	result := make([]byte, size)
+	if _, err := rand.Read(result); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(result)
+}
@@ -8,3 +8,21 @@ import (
 func IsRequeued(result ctrl.Result, err error) bool {
 	return err != nil || result.Requeue || result.RequeueAfter > 0
 }
+
+// EarliestRequeue returns the result requeuing the soonest, a requeue without delay
+// being sooner than any delayed one
+func EarliestRequeue(result ctrl.Result, other ctrl.Result) ctrl.Result {
+	switch {
+	case !IsRequeued(result, nil):
+		return other
+	case !IsRequeued(other, nil):
+		return result
+	case result.RequeueAfter == 0:
+		return result
+	case other.RequeueAfter == 0:
+		return other
+	case other.RequeueAfter < result.RequeueAfter:
+		return other
+	}
+	return result
+}
@@ -0,0 +1,71 @@
+package vault
+
+import (
+	"fmt"
+)
+
+// InitRequest is the payload used to initialize Vault
+type InitRequest struct {
+	SecretShares    int `json:"secret_shares"`
+	SecretThreshold int `json:"secret_threshold"`
+}
+
+// InitResponse holds the unseal keys and the root token returned by the initialization
+type InitResponse struct {
+	Keys       []string `json:"keys"`
+	KeysBase64 []string `json:"keys_base64"`
+	RootToken  string   `json:"root_token"`
+}
+
+// InitStatus tells whether Vault is initialized
+type InitStatus struct {
+	Initialized bool `json:"initialized"`
+}
+
+// SealStatus tells whether Vault is sealed
+type SealStatus struct {
+	Sealed    bool `json:"sealed"`
+	Threshold int  `json:"t"`
+	Progress  int  `json:"progress"`
+}
+
+// UnsealRequest is the payload used to provide an unseal key
+type UnsealRequest struct {
+	Key string `json:"key"`
+}
+
+// NewInitRequest creates the initialization request
+func NewInitRequest() *InitRequest {
+	return &InitRequest{
+		SecretShares:    5,
+		SecretThreshold: 3,
+	}
+}
+
+// NewUserPolicy returns the policy giving a user full access to its own KV path
+func NewUserPolicy(username string) string {
+	return fmt.Sprintf(`path "secret/data/%[1]s/*" {
+  capabilities = ["create", "read", "update", "delete", "list"]
+}
+path "secret/metadata/%[1]s/*" {
+  capabilities = ["read", "delete", "list"]
+}
+`, username)
+}
+
+// NewKubernetesRole returns the Kubernetes auth role binding the service accounts of namespaces to a policy
+func NewKubernetesRole(namespaces []string, policy string) map[string]interface{} {
+	return map[string]interface{}{
+		"bound_service_account_names":      []string{"*"},
+		"bound_service_account_namespaces": namespaces,
+		"policies":                         []string{policy},
+		"ttl":                              "24h",
+	}
+}
+
+// LeaderStatus tells whether a Vault server is the active node of the cluster
+type LeaderStatus struct {
+	HAEnabled     bool   `json:"ha_enabled"`
+	IsSelf        bool   `json:"is_self"`
+	LeaderAddress string `json:"leader_address"`
+}
@@ -0,0 +1,46 @@
+package vault
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewConfiguration returns the server configuration using file storage
+// or, for more than one replica, a Raft cluster joined through the internal service
+func NewConfiguration(name string, replicas int32) string {
+	configuration := `disable_mlock = true
+ui = true
+
+listener "tcp" {
+	tls_disable = 1
+	address = "[::]:8200"
+	cluster_address = "[::]:8201"
+}
+`
+
+	if replicas <= 1 {
+		return configuration + `storage "file" {
+	path = "/vault/data"
+}
+`
+	}
+
+	configuration += `storage "raft" {
+	path = "/vault/data"
+`
+	for id := int32(0); id < replicas; id++ {
+		configuration += fmt.Sprintf(`
+	retry_join {
+		leader_api_addr = "http://%[1]s-%[2]d.%[1]s-internal:8200"
+	}
+`, name, id)
+	}
+
+	return configuration + `}
+`
+}
+
+// IsRaftStorage returns true when the server configuration uses Raft storage
+func IsRaftStorage(configuration string) bool {
+	return strings.Contains(configuration, `storage "raft"`)
+}
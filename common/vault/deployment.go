@@ -12,7 +12,7 @@ import (
 
 // NewAgentInjectorDeployment creates a Deployment
 func NewAgentInjectorDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
-	name string, namespace string, labels map[string]string) *appsv1.Deployment {
+	name string, namespace string, labels map[string]string, tlsSecretName string) *appsv1.Deployment {
 
 	image := workshop.Spec.Infrastructure.Vault.AgentInjectorImage.Name + ":" + workshop.Spec.Infrastructure.Vault.AgentInjectorImage.Tag
 	vaultImage := workshop.Spec.Infrastructure.Vault.Image.Name + ":" + workshop.Spec.Infrastructure.Vault.Image.Tag
@@ -22,6 +22,7 @@ func NewAgentInjectorDeployment(workshop *workshopv1.Workshop, scheme *runtime.S
 	runAsNonRoot := true
 	runAsGroup := int64(1000)
 	runAsUser := int64(100)
+	defaultMode := int32(420)
 
 	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -43,6 +44,17 @@ func NewAgentInjectorDeployment(workshop *workshopv1.Workshop, scheme *runtime.S
 						RunAsGroup:   &runAsGroup,
 						RunAsUser:    &runAsUser,
 					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "webhook-certs",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName:  tlsSecretName,
+									DefaultMode: &defaultMode,
+								},
+							},
+						},
+					},
 					Containers: []corev1.Container{
 						{
 							Name:            "sidecar-injector",
@@ -70,12 +82,12 @@ func NewAgentInjectorDeployment(workshop *workshopv1.Workshop, scheme *runtime.S
 									Value: vaultImage,
 								},
 								{
-									Name:  "AGENT_INJECT_TLS_AUTO",
-									Value: name + "-cfg",
+									Name:  "AGENT_INJECT_TLS_CERT_FILE",
+									Value: "/etc/webhook/certs/tls.crt",
 								},
 								{
-									Name:  "AGENT_INJECT_TLS_AUTO_HOSTS",
-									Value: "vault-agent-injector,vault-agent-injector." + namespace + ",vault-agent-injector." + namespace + "svc",
+									Name:  "AGENT_INJECT_TLS_KEY_FILE",
+									Value: "/etc/webhook/certs/tls.key",
 								},
 								{
 									Name:  "AGENT_INJECT_LOG_FORMAT",
@@ -124,6 +136,13 @@ func NewAgentInjectorDeployment(workshop *workshopv1.Workshop, scheme *runtime.S
 								SuccessThreshold:    1,
 								TimeoutSeconds:      5,
 							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "webhook-certs",
+									MountPath: "/etc/webhook/certs",
+									ReadOnly:  true,
+								},
+							},
 						},
 					},
 				},
@@ -12,11 +12,9 @@ import (
 
 // NewStatefulSet creates a statefulset
 func NewStatefulSet(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
-	name string, namespace string, labels map[string]string) *appsv1.StatefulSet {
+	name string, namespace string, labels map[string]string, replicas int32) *appsv1.StatefulSet {
 
 	image := workshop.Spec.Infrastructure.Vault.Image.Name + ":" + workshop.Spec.Infrastructure.Vault.Image.Tag
-
-	replicas := int32(1)
 	terminationGracePeriodSeconds := int64(10)
 
 	runAsNonRoot := true
@@ -141,6 +139,10 @@ sed -Ei "s/POD_IP/${POD_IP?}/g" /tmp/storageconfig.hcl;
 										},
 									},
 								},
+								{
+									Name:  "VAULT_CLUSTER_ADDR",
+									Value: "https://$(HOSTNAME)." + name + "-internal:8201",
+								},
 							},
 							VolumeMounts: []corev1.VolumeMount{
 								{
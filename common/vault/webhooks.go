@@ -2,23 +2,42 @@ package vault
 
 import (
 	admissionregistration "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // NewAgentInjectorWebHook create webhook
-func NewAgentInjectorWebHook(namespace string) []admissionregistration.MutatingWebhook {
+func NewAgentInjectorWebHook(namespace string, caBundle []byte) []admissionregistration.MutatingWebhook {
 	path := "/mutate"
+	timeoutSeconds := int32(5)
+	// Pod creation must not be blocked when the injector is unavailable
+	failurePolicy := admissionregistration.Ignore
+	sideEffects := admissionregistration.SideEffectClassNone
 
 	return []admissionregistration.MutatingWebhook{
 		{
 			Name: "vault.hashicorp.com",
 			ClientConfig: admissionregistration.WebhookClientConfig{
-				CABundle: []byte{},
+				CABundle: caBundle,
 				Service: &admissionregistration.ServiceReference{
 					Name:      "vault-agent-injector",
 					Namespace: namespace,
 					Path:      &path,
 				},
 			},
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			TimeoutSeconds:          &timeoutSeconds,
+			AdmissionReviewVersions: []string{"v1beta1", "v1"},
+			// Skip the critical namespaces of the platform
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "openshift.io/run-level",
+						Operator: metav1.LabelSelectorOpNotIn,
+						Values:   []string{"0", "1"},
+					},
+				},
+			},
 			Rules: []admissionregistration.RuleWithOperations{
 				{
 					Operations: []admissionregistration.OperationType{
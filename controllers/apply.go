@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/prometheus/common/log"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// applyUnstructured creates or updates the object with a server-side apply
+func (r *WorkshopReconciler) applyUnstructured(obj *unstructured.Unstructured) error {
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if err := r.Patch(context.TODO(), obj, client.Apply,
+		client.FieldOwner("workshop-operator"), client.ForceOwnership); err != nil {
+		return err
+	}
+
+	if found.GetResourceVersion() == "" {
+		log.Infof("Created %s %s", obj.GetName(), obj.GetKind())
+	} else if found.GetResourceVersion() != obj.GetResourceVersion() {
+		log.Infof("Updated %s %s", obj.GetName(), obj.GetKind())
+	}
+
+	return nil
+}
@@ -9,7 +9,6 @@ import (
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
 	"github.com/mcouliba/workshop-operator/common/bookbag"
 	"github.com/mcouliba/workshop-operator/common/kubernetes"
-	routev1 "github.com/openshift/api/route/v1"
 	"github.com/prometheus/common/log"
 
 	"github.com/mcouliba/workshop-operator/common/util"
@@ -25,7 +24,7 @@ func (r *WorkshopReconciler) reconcileBookbag(workshop *workshopv1.Workshop, use
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
 	enabled := workshop.Spec.Infrastructure.Guide.Bookbag.Enabled
 
-	guidesNamespace := "workshop-guides"
+	guidesNamespace := workshop.Status.Namespaces.Guide
 
 	id := 1
 	for {
@@ -141,12 +140,9 @@ func (r *WorkshopReconciler) addUpdateBookbag(workshop *workshopv1.Workshop, use
 		log.Infof("Created %s Service", service.Name)
 	}
 
-	// Create Route
-	route := kubernetes.NewRoute(workshop, r.Scheme, bookbagName, namespace.Name, labels, bookbagName, 10080)
-	if err := r.Create(context.TODO(), route); err != nil && !errors.IsAlreadyExists(err) {
+	// Expose Service
+	if _, err := r.exposeService(workshop, bookbagName, namespace.Name, labels, bookbagName, 10080, false, appsHostnameSuffix); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Route", route.Name)
 	}
 
 	//Success
@@ -157,14 +153,9 @@ func (r *WorkshopReconciler) deleteBookbag(workshop *workshopv1.Workshop, userID
 
 	bookbagName := fmt.Sprintf("user%s-bookbag", userID)
 
-	routeFound := &routev1.Route{}
-	routeErr := r.Get(context.TODO(), types.NamespacedName{Name: bookbagName, Namespace: guidesNamespace}, routeFound)
-	if routeErr == nil {
-		// Delete Route
-		if err := r.Delete(context.TODO(), routeFound); err != nil {
-			return reconcile.Result{}, err
-		}
-		log.Infof("Deleted %s Route", routeFound.Name)
+	// Delete Route or Ingress
+	if err := r.deleteExposure(bookbagName, guidesNamespace); err != nil {
+		return reconcile.Result{}, err
 	}
 
 	serviceFound := &corev1.Service{}
@@ -2,13 +2,18 @@ package controllers
 
 import (
 	"context"
+	"reflect"
 
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
 	certmanager "github.com/mcouliba/workshop-operator/common/certmanager"
 	"github.com/mcouliba/workshop-operator/common/kubernetes"
 	"github.com/mcouliba/workshop-operator/common/util"
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/prometheus/common/log"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -31,36 +36,222 @@ func (r *WorkshopReconciler) addCertManager(workshop *workshopv1.Workshop, users
 	channel := workshop.Spec.Infrastructure.CertManager.OperatorHub.Channel
 	clusterServiceVersion := workshop.Spec.Infrastructure.CertManager.OperatorHub.ClusterServiceVersion
 
-	CertManagerSubscription := kubernetes.NewCertifiedSubscription(workshop, r.Scheme, "cert-manager-operator", "openshift-operators",
-		"cert-manager-operator", channel, clusterServiceVersion)
+	labels := map[string]string{
+		"app.kubernetes.io/part-of": "certmanager",
+	}
+
+	name := "cert-manager-operator"
+	certManagerNamespace := workshop.Status.Namespaces.CertManager
+	CertManagerSubscription := kubernetes.NewCertifiedSubscription(workshop, r.Scheme, name, r.operatorsNamespace(),
+		name, channel, clusterServiceVersion)
+	if r.Platform != kubernetes.OpenShift {
+		// OperatorHub.io ships cert-manager itself
+		name = "cert-manager"
+		CertManagerSubscription = kubernetes.NewCommunitySubscription(workshop, r.Scheme, name, r.operatorsNamespace(),
+			name, channel, clusterServiceVersion)
+		r.useCatalog(CertManagerSubscription)
+	}
 	if err := r.Create(context.TODO(), CertManagerSubscription); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Subscription", CertManagerSubscription.Name)
 	}
+	if err := r.acquireShared(workshop, CertManagerSubscription); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// Approve the installation
-	if err := r.ApproveInstallPlan(clusterServiceVersion, "cert-manager-operator", "openshift-operators"); err != nil {
-		log.Infof("Waiting for Subscription to create InstallPlan for %s", "CertManageroperator")
+	if err := r.ApproveInstallPlan(clusterServiceVersion, name, r.operatorsNamespace()); err != nil {
+		log.Infof("Waiting for Subscription to create InstallPlan for %s", name)
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	if r.Platform == kubernetes.OpenShift {
+		namespace := kubernetes.NewNamespace(workshop, r.Scheme, certManagerNamespace)
+		if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Created %s Namespace", namespace.Name)
+		}
+		if err := r.acquireSharedNamespace(workshop, namespace); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		customresource := certmanager.NewCustomResource(workshop, r.Scheme, "cert-manager", namespace.Name, labels)
+		if err := r.Create(context.TODO(), customresource); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Created %s Custom Resource", customresource.Name)
+		}
+		if err := r.acquireShared(workshop, customresource); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Wait for the webhook to validate the issuers and certificates
+	if !kubernetes.GetK8Client().GetDeploymentStatus("cert-manager-webhook", certManagerNamespace) {
 		return reconcile.Result{Requeue: true}, nil
 	}
 
-	namespace := kubernetes.NewNamespace(workshop, r.Scheme, "cert-manager")
-	if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
+	issuerName, result, err := r.addClusterIssuer(workshop, certManagerNamespace, labels)
+	if util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	if r.Platform == kubernetes.OpenShift {
+		if result, err := r.addRouteCertificates(workshop, issuerName); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func (r *WorkshopReconciler) addClusterIssuer(workshop *workshopv1.Workshop, namespace string,
+	labels map[string]string) (string, reconcile.Result, error) {
+
+	issuer := workshop.Spec.Infrastructure.CertManager.Issuer
+
+	if issuer.Type == "ACME" {
+		acmeIssuer := certmanager.NewACMEClusterIssuer(workshop, r.Scheme, "workshop-acme", labels,
+			issuer.ACME.Server, issuer.ACME.Email, issuer.ACME.SkipTLSVerify)
+		if result, err := r.addClusterIssuerCR(workshop, acmeIssuer); util.IsRequeued(result, err) {
+			return "", result, err
+		}
+		return acmeIssuer.Name, reconcile.Result{}, nil
+	}
+
+	// Self-signed CA
+	selfSignedIssuer := certmanager.NewSelfSignedClusterIssuer(workshop, r.Scheme, "workshop-selfsigned", labels)
+	if result, err := r.addClusterIssuerCR(workshop, selfSignedIssuer); util.IsRequeued(result, err) {
+		return "", result, err
+	}
+
+	caCertificate := certmanager.NewCACertificate(workshop, r.Scheme, "workshop-ca", namespace, labels,
+		"workshop-ca", "workshop-ca", selfSignedIssuer.Name)
+	if err := r.Create(context.TODO(), caCertificate); err != nil && !errors.IsAlreadyExists(err) {
+		return "", reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Certificate", caCertificate.Name)
+	}
+	if err := r.acquireShared(workshop, caCertificate); err != nil {
+		return "", reconcile.Result{}, err
+	}
+
+	caIssuer := certmanager.NewCAClusterIssuer(workshop, r.Scheme, "workshop-ca", labels, caCertificate.Spec.SecretName)
+	if result, err := r.addClusterIssuerCR(workshop, caIssuer); util.IsRequeued(result, err) {
+		return "", result, err
+	}
+
+	return caIssuer.Name, reconcile.Result{}, nil
+}
+
+func (r *WorkshopReconciler) addClusterIssuerCR(workshop *workshopv1.Workshop, issuer *certmanager.ClusterIssuer) (reconcile.Result, error) {
+
+	if err := r.Create(context.TODO(), issuer); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Namespace", namespace.Name)
+		log.Infof("Created %s ClusterIssuer", issuer.Name)
+	} else if errors.IsAlreadyExists(err) {
+		issuerFound := &certmanager.ClusterIssuer{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: issuer.Name}, issuerFound); err != nil {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			if !reflect.DeepEqual(issuer.Spec, issuerFound.Spec) {
+				issuerFound.Spec = issuer.Spec
+				if err := r.Update(context.TODO(), issuerFound); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s ClusterIssuer", issuerFound.Name)
+			}
+		}
+	}
+
+	// The issuers are shared by the Workshops of the cluster
+	if err := r.acquireShared(workshop, issuer); err != nil {
+		return reconcile.Result{}, err
 	}
 
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// addRouteCertificates issues a certificate for each Route of the Workshop
+// and copies it into the Route TLS configuration once it is available
+func (r *WorkshopReconciler) addRouteCertificates(workshop *workshopv1.Workshop, issuerName string) (reconcile.Result, error) {
+
 	labels := map[string]string{
 		"app.kubernetes.io/part-of": "certmanager",
 	}
 
-	customresource := certmanager.NewCustomResource(workshop, r.Scheme, "cert-manager", namespace.Name, labels)
-	if err := r.Create(context.TODO(), customresource); err != nil && !errors.IsAlreadyExists(err) {
+	routes := &routev1.RouteList{}
+	if err := r.List(context.TODO(), routes, client.MatchingLabels{kubernetes.WorkshopLabel: kubernetes.WorkshopLabelValue(workshop)}); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Custom Resource", customresource.Name)
+	}
+
+	pending := false
+	for i := range routes.Items {
+		route := &routes.Items[i]
+		if route.Spec.Host == "" || (route.Spec.TLS != nil && route.Spec.TLS.Termination == routev1.TLSTerminationPassthrough) {
+			continue
+		}
+
+		certificate := certmanager.NewCertificate(workshop, r.Scheme, route.Name, route.Namespace, labels,
+			[]string{route.Spec.Host}, route.Name+"-tls", issuerName)
+		if err := r.Create(context.TODO(), certificate); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Created %s Certificate in %s", certificate.Name, certificate.Namespace)
+		} else if errors.IsAlreadyExists(err) {
+			certificateFound := &certmanager.Certificate{}
+			if err := r.Get(context.TODO(), types.NamespacedName{Name: certificate.Name, Namespace: certificate.Namespace}, certificateFound); err != nil {
+				return reconcile.Result{}, err
+			} else if err == nil {
+				if !reflect.DeepEqual(certificate.Spec, certificateFound.Spec) {
+					certificateFound.Spec = certificate.Spec
+					if err := r.Update(context.TODO(), certificateFound); err != nil {
+						return reconcile.Result{}, err
+					}
+					log.Infof("Updated %s Certificate in %s", certificateFound.Name, certificateFound.Namespace)
+				}
+			}
+		}
+
+		secret := &corev1.Secret{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: certificate.Spec.SecretName, Namespace: route.Namespace}, secret); err != nil {
+			if errors.IsNotFound(err) {
+				log.Infof("Waiting for %s Certificate to be issued", certificate.Name)
+				pending = true
+				continue
+			}
+			return reconcile.Result{}, err
+		}
+
+		tls := &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationEdge,
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyAllow,
+			Certificate:                   string(secret.Data[corev1.TLSCertKey]),
+			Key:                           string(secret.Data[corev1.TLSPrivateKeyKey]),
+			CACertificate:                 string(secret.Data["ca.crt"]),
+		}
+		if route.Spec.TLS != nil {
+			tls.Termination = route.Spec.TLS.Termination
+			tls.InsecureEdgeTerminationPolicy = route.Spec.TLS.InsecureEdgeTerminationPolicy
+			tls.DestinationCACertificate = route.Spec.TLS.DestinationCACertificate
+		}
+
+		if !reflect.DeepEqual(tls, route.Spec.TLS) {
+			route.Spec.TLS = tls
+			if err := r.Update(context.TODO(), route); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Updated %s Route TLS certificate in %s", route.Name, route.Namespace)
+		}
+	}
+
+	if pending {
+		return reconcile.Result{Requeue: true}, nil
 	}
 
 	//Success
@@ -16,9 +16,11 @@ import (
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
 	"github.com/mcouliba/workshop-operator/common/codeready"
 	"github.com/mcouliba/workshop-operator/common/kubernetes"
+	"github.com/mcouliba/workshop-operator/common/nexus"
 	"github.com/mcouliba/workshop-operator/common/util"
 	"github.com/prometheus/common/log"
 
+	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/yaml"
@@ -30,6 +32,11 @@ func (r *WorkshopReconciler) reconcileCodeReadyWorkspace(workshop *workshopv1.Wo
 	enabled := workshop.Spec.Infrastructure.CodeReadyWorkspace.Enabled
 
 	if enabled {
+		// The users log in to CodeReady Workspaces through the OpenShift OAuth server
+		if unsupported, err := r.isOpenShiftOnly(workshop, workshopv1.WorkshopCodeReadyWorkspaceSupported, "CodeReady Workspaces", true); unsupported || err != nil {
+			return reconcile.Result{}, err
+		}
+
 		if result, err := r.addCodeReadyWorkspace(workshop, users, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
 			return result, err
 		}
@@ -45,7 +52,7 @@ func (r *WorkshopReconciler) addCodeReadyWorkspace(workshop *workshopv1.Workshop
 	channel := workshop.Spec.Infrastructure.CodeReadyWorkspace.OperatorHub.Channel
 	clusterServiceVersion := workshop.Spec.Infrastructure.CodeReadyWorkspace.OperatorHub.ClusterServiceVersion
 
-	codeReadyWorkspacesNamespace := kubernetes.NewNamespace(workshop, r.Scheme, "workspaces")
+	codeReadyWorkspacesNamespace := kubernetes.NewNamespace(workshop, r.Scheme, workshop.Status.Namespaces.CodeReadyWorkspace)
 	if err := r.Create(context.TODO(), codeReadyWorkspacesNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
@@ -109,14 +116,14 @@ func (r *WorkshopReconciler) addCodeReadyWorkspace(workshop *workshopv1.Workshop
 
 		// Che Cluster Role
 		cheClusterRole :=
-			kubernetes.NewClusterRole(workshop, r.Scheme, "che", codeReadyWorkspacesNamespace.Name, labels, kubernetes.CheRules())
+			kubernetes.NewClusterRole(workshop, r.Scheme, kubernetes.PrefixedName(workshop, "che"), codeReadyWorkspacesNamespace.Name, labels, kubernetes.CheRules())
 		if err := r.Create(context.TODO(), cheClusterRole); err != nil && !errors.IsAlreadyExists(err) {
 			return reconcile.Result{}, err
 		} else if err == nil {
 			log.Infof("Created %s Cluster Role", cheClusterRole.Name)
 		}
 
-		cheClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, "che", codeReadyWorkspacesNamespace.Name, labels, "che", cheClusterRole.Name, "ClusterRole")
+		cheClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, kubernetes.PrefixedName(workshop, "che"), codeReadyWorkspacesNamespace.Name, labels, "che", cheClusterRole.Name, "ClusterRole")
 		if err := r.Create(context.TODO(), cheClusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
 			return reconcile.Result{}, err
 		} else if err == nil {
@@ -135,6 +142,10 @@ func (r *WorkshopReconciler) addCodeReadyWorkspace(workshop *workshopv1.Workshop
 				return result, err
 			}
 
+			if result, err := r.addWorkspaceNamespace(workshop, username); util.IsRequeued(result, err) {
+				return result, err
+			}
+
 			if result, err := initWorkspace(workshop, username, "codeready", codeReadyWorkspacesNamespace.Name, userAccessToken, devfile, appsHostnameSuffix); err != nil {
 				return result, err
 			}
@@ -153,6 +164,10 @@ func (r *WorkshopReconciler) addCodeReadyWorkspace(workshop *workshopv1.Workshop
 				return result, err
 			}
 
+			if result, err := r.addWorkspaceNamespace(workshop, username); util.IsRequeued(result, err) {
+				return result, err
+			}
+
 			if result, err := initWorkspace(workshop, username, "codeready", codeReadyWorkspacesNamespace.Name, userAccessToken, devfile, appsHostnameSuffix); err != nil {
 				return result, err
 			}
@@ -163,6 +178,61 @@ func (r *WorkshopReconciler) addCodeReadyWorkspace(workshop *workshopv1.Workshop
 	return reconcile.Result{}, nil
 }
 
+// addWorkspaceNamespace provisions the workspace namespace of a user with the tooling settings
+func (r *WorkshopReconciler) addWorkspaceNamespace(workshop *workshopv1.Workshop, username string) (reconcile.Result, error) {
+
+	labels := map[string]string{
+		"app.kubernetes.io/part-of": "codeready",
+	}
+
+	workspaceNamespace := codeready.NewWorkspaceNamespace(workshop, r.Scheme, codeready.WorkspaceNamespaceName(workshop, username), username)
+	if err := r.Create(context.TODO(), workspaceNamespace); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Namespace", workspaceNamespace.Name)
+	}
+
+	users := []rbac.Subject{
+		{
+			Kind: rbac.UserKind,
+			Name: username,
+		},
+	}
+	userRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, username+"-workspace", workspaceNamespace.Name, labels,
+		users, "admin", "ClusterRole")
+	if err := r.Create(context.TODO(), userRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Role Binding", userRoleBinding.Name)
+	}
+
+	if result, err := r.manageQuota(workshop, workspaceNamespace.Name, username); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	if workshop.Spec.Infrastructure.Nexus.Enabled {
+		nexusNamespace := workshop.Status.Namespaces.Nexus
+		mavenSettings := map[string]string{
+			"settings.xml": nexus.NewMavenSettings(nexusNamespace, username, workshop.Spec.User.Password),
+		}
+		mavenSecret := codeready.NewWorkspaceSecret(workshop, r.Scheme, "nexus-maven-settings", workspaceNamespace.Name, "/home/user/.m2", mavenSettings)
+		if result, err := r.manageSettingsSecret(mavenSecret); util.IsRequeued(result, err) {
+			return result, err
+		}
+
+		npmSettings := map[string]string{
+			".npmrc": nexus.NewNpmrc(nexusNamespace, username, workshop.Spec.User.Password),
+		}
+		npmSecret := codeready.NewWorkspaceSecret(workshop, r.Scheme, "nexus-npm-settings", workspaceNamespace.Name, "/home/user", npmSettings)
+		if result, err := r.manageSettingsSecret(npmSecret); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
 func getDevFile(workshop *workshopv1.Workshop) (string, reconcile.Result, error) {
 
 	var (
@@ -304,6 +374,7 @@ func getUserToken(workshop *workshopv1.Workshop, username string, codeflavor str
 	return userToken.AccessToken, reconcile.Result{}, nil
 }
 
+// getOAuthUserToken logs the user in through the OpenShift OAuth server and returns the access token
 func getOAuthUserToken(workshop *workshopv1.Workshop, username string,
 	codeflavor string, namespace string, appsHostnameSuffix string) (string, reconcile.Result, error) {
 	var (
@@ -531,3 +602,120 @@ func initWorkspace(workshop *workshopv1.Workshop, username string,
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// stopWorkspaces stops the running workspaces of every user through the Che API
+// and requeues until all of them are reported STOPPED
+func stopWorkspaces(workshop *workshopv1.Workshop, users int, codeflavor string, namespace string,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+
+	for id := 1; id <= users; id++ {
+		username := fmt.Sprintf("user%d", id)
+
+		var (
+			userAccessToken string
+			result          reconcile.Result
+			err             error
+		)
+		if workshop.Spec.Infrastructure.CodeReadyWorkspace.OpenshiftOAuth {
+			userAccessToken, result, err = getOAuthUserToken(workshop, username, codeflavor, namespace, appsHostnameSuffix)
+		} else {
+			userAccessToken, result, err = getUserToken(workshop, username, codeflavor, namespace, appsHostnameSuffix)
+		}
+		if util.IsRequeued(result, err) {
+			return result, err
+		}
+
+		if result, err := stopUserWorkspaces(username, codeflavor, namespace, userAccessToken, appsHostnameSuffix); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func stopUserWorkspaces(username string, codeflavor string, namespace string, userAccessToken string,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+
+	var (
+		err          error
+		httpResponse *http.Response
+		httpRequest  *http.Request
+		workspaceURL = "https://" + codeflavor + "-" + namespace + "." + appsHostnameSuffix + "/api/workspace"
+
+		workspaces []struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		}
+		client = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+			// Do not follow Redirect
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	)
+
+	// GET WORKSPACES
+	httpRequest, err = http.NewRequest("GET", workspaceURL, nil)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	httpRequest.Header.Set("Authorization", "Bearer "+userAccessToken)
+	httpRequest.Header.Set("Accept", "application/json")
+
+	httpResponse, err = client.Do(httpRequest)
+	if err != nil {
+		log.Errorf("Error when getting the workspaces of %s: %v", username, err)
+		return reconcile.Result{}, err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		log.Errorf("Error when getting the workspaces of %s (%d)", username, httpResponse.StatusCode)
+		return reconcile.Result{Requeue: true}, nil
+	}
+	if err := json.NewDecoder(httpResponse.Body).Decode(&workspaces); err != nil {
+		log.Errorf("Error when reading the workspaces of %s: %v", username, err)
+		return reconcile.Result{}, err
+	}
+
+	// STOP WORKSPACES
+	stopping := false
+	for _, workspace := range workspaces {
+		if workspace.Status == "STOPPED" {
+			continue
+		}
+		stopping = true
+		if workspace.Status == "STOPPING" {
+			continue
+		}
+
+		httpRequest, err = http.NewRequest("DELETE", workspaceURL+"/"+workspace.ID+"/runtime", nil)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		httpRequest.Header.Set("Authorization", "Bearer "+userAccessToken)
+
+		stopResponse, err := client.Do(httpRequest)
+		if err != nil {
+			log.Errorf("Error when stopping the workspace %s of %s: %v", workspace.ID, username, err)
+			return reconcile.Result{}, err
+		}
+		stopResponse.Body.Close()
+		if stopResponse.StatusCode != http.StatusNoContent {
+			log.Errorf("Error when stopping the workspace %s of %s (%d)", workspace.ID, username, stopResponse.StatusCode)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+		log.Infof("Stopping the workspace %s of %s", workspace.ID, username)
+	}
+
+	// Wait for the workspaces to be stopped before scaling down CodeReady Workspaces
+	if stopping {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
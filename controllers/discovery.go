@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/kubernetes"
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// discoverCluster returns the apps domain and the console URL of the cluster
+func (r *WorkshopReconciler) discoverCluster(workshop *workshopv1.Workshop) (string, string, error) {
+	appsHostnameSuffix := workshop.Spec.AppsDomain
+
+	// Kubernetes has neither a cluster Ingress config nor a console
+	if r.Platform != kubernetes.OpenShift {
+		if appsHostnameSuffix == "" {
+			return "", "", fmt.Errorf("spec.appsDomain is required on %s", r.Platform)
+		}
+		return appsHostnameSuffix, "", nil
+	}
+
+	if appsHostnameSuffix == "" {
+		ingress := &configv1.Ingress{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, ingress); err != nil {
+			return "", "", fmt.Errorf("failed to get the cluster Ingress config: %v", err)
+		}
+		appsHostnameSuffix = ingress.Spec.Domain
+	}
+	if appsHostnameSuffix == "" {
+		return "", "", fmt.Errorf("the cluster Ingress config has no domain, set spec.appsDomain")
+	}
+
+	console := &configv1.Console{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, console); err != nil {
+		return "", "", fmt.Errorf("failed to get the cluster Console config: %v", err)
+	}
+	openshiftConsoleURL := strings.TrimSuffix(console.Status.ConsoleURL, "/")
+	if openshiftConsoleURL == "" {
+		return "", "", fmt.Errorf("the cluster Console config has no URL yet")
+	}
+
+	return appsHostnameSuffix, openshiftConsoleURL, nil
+}
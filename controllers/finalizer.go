@@ -9,10 +9,29 @@ import (
 )
 
 func (r *WorkshopReconciler) finalizeWorkshop(reqLogger logr.Logger, workshop *workshopv1.Workshop) error {
-	// TODO(user): Add the cleanup steps that the operator
-	// needs to do before the CR can be deleted. Examples
-	// of finalizers include performing backups and deleting
-	// resources that are not owned by this CR, like a PVC.
+	// Cluster-scoped resources cannot be owned by the Workshop
+	// and are not garbage collected
+	if err := r.deleteSecurityContextConstraints(workshop); err != nil {
+		reqLogger.Error(err, "Failed to delete the SCCs of the Workshop")
+		return err
+	}
+
+	// The shared Argo CD instance keeps the policy of the other Workshops
+	if err := r.releaseDefaultArgoCD(workshop); err != nil {
+		reqLogger.Error(err, "Failed to remove the Workshop policy from the default Argo CD instance")
+		return err
+	}
+
+	// Shared resources are deleted with the last Workshop using them
+	if err := r.releaseShared(workshop); err != nil {
+		reqLogger.Error(err, "Failed to release the shared resources of the Workshop")
+		return err
+	}
+
+	r.seedCacheMutex.Lock()
+	delete(r.seedCache, workshopKey(workshop))
+	r.seedCacheMutex.Unlock()
+
 	reqLogger.Info("Successfully finalized workshop")
 	return nil
 }
@@ -1,118 +1,157 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/url"
-	"strconv"
-	"strings"
 	"time"
 
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
 	"github.com/mcouliba/workshop-operator/common/gitea"
 	"github.com/mcouliba/workshop-operator/common/kubernetes"
-	routev1 "github.com/openshift/api/route/v1"
 	"github.com/prometheus/common/log"
 
 	"github.com/mcouliba/workshop-operator/common/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // Reconciling Gitea
-func (r *WorkshopReconciler) reconcileGitea(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
+func (r *WorkshopReconciler) reconcileGitea(workshop *workshopv1.Workshop, users int,
+	appsHostnameSuffix string) (reconcile.Result, error) {
 	enabledGitea := workshop.Spec.Infrastructure.Gitea.Enabled
 
 	if enabledGitea {
-		if result, err := r.addGitea(workshop, users); util.IsRequeued(result, err) {
+		if err := r.updateComponentStatus(workshop, &workshop.Status.Gitea, util.OperatorStatus.InProgress); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if result, err := r.addGitea(workshop, users, appsHostnameSuffix); util.IsRequeued(result, err) {
 			return result, err
 		}
+
+		if err := r.updateComponentStatus(workshop, &workshop.Status.Gitea, util.OperatorStatus.Installed); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else {
+		if err := r.updateComponentStatus(workshop, &workshop.Status.Gitea, util.OperatorStatus.NotScheduled); err != nil {
+			return reconcile.Result{}, err
+		}
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) addGitea(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
-
-	imageName := workshop.Spec.Infrastructure.Gitea.Image.Name
-	imageTag := workshop.Spec.Infrastructure.Gitea.Image.Tag
+func (r *WorkshopReconciler) addGitea(workshop *workshopv1.Workshop, users int,
+	appsHostnameSuffix string) (reconcile.Result, error) {
 
+	serviceName := "gitea-server"
 	labels := map[string]string{
+		"app":                       serviceName,
 		"app.kubernetes.io/part-of": "gitea",
 	}
 
-	giteaNamespace := kubernetes.NewNamespace(workshop, r.Scheme, "gitea")
+	giteaNamespace := kubernetes.NewNamespace(workshop, r.Scheme, workshop.Status.Namespaces.Gitea)
 	if err := r.Create(context.TODO(), giteaNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Project", giteaNamespace.Name)
 	}
 
-	giteaCustomResourceDefinition := kubernetes.NewCustomResourceDefinition(workshop, r.Scheme, "giteas.gpte.opentlc.com", "gpte.opentlc.com", "Gitea", "GiteaList", "giteas", "gitea", "v1alpha1", nil, nil)
-	if err := r.Create(context.TODO(), giteaCustomResourceDefinition); err != nil && !errors.IsAlreadyExists(err) {
+	// Admin credentials are generated once and kept in a Secret
+	adminCredentials := map[string]string{
+		"username": "workshop-admin",
+		"password": util.RandomString(16),
+	}
+	adminSecret := kubernetes.NewStringDataSecret(workshop, r.Scheme, serviceName+"-admin", giteaNamespace.Name, labels, adminCredentials)
+	if err := r.Create(context.TODO(), adminSecret); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Custom Resource Definition", giteaCustomResourceDefinition.Name)
+		log.Infof("Created %s Secret", adminSecret.Name)
 	}
 
-	giteaServiceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, "gitea-operator", giteaNamespace.Name, labels)
-	if err := r.Create(context.TODO(), giteaServiceAccount); err != nil && !errors.IsAlreadyExists(err) {
+	adminSecretFound := &corev1.Secret{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: adminSecret.Name, Namespace: giteaNamespace.Name}, adminSecretFound); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Service Account", giteaServiceAccount.Name)
 	}
 
-	giteaClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, "gitea-operator", giteaNamespace.Name, labels, kubernetes.GiteaRules())
-	if err := r.Create(context.TODO(), giteaClusterRole); err != nil && !errors.IsAlreadyExists(err) {
+	// app.ini holds the server secrets so it is only generated once
+	giteaHost := serviceName + "-" + giteaNamespace.Name + "." + appsHostnameSuffix
+	configuration := map[string]string{
+		"app.ini": gitea.NewConfiguration(giteaHost, util.RandomString(64), util.RandomString(105), util.RandomBase64(32)),
+	}
+	configSecret := kubernetes.NewStringDataSecret(workshop, r.Scheme, serviceName+"-config", giteaNamespace.Name, labels, configuration)
+	if err := r.Create(context.TODO(), configSecret); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Cluster Role", giteaClusterRole.Name)
+		log.Infof("Created %s Secret", configSecret.Name)
 	}
 
-	giteaClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, "gitea-operator", giteaNamespace.Name, labels, "gitea-operator", "gitea-operator", "ClusterRole")
-	if err := r.Create(context.TODO(), giteaClusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	persistentVolumeClaim := kubernetes.NewPersistentVolumeClaim(workshop, r.Scheme, serviceName, giteaNamespace.Name, labels, "5Gi")
+	if err := r.Create(context.TODO(), persistentVolumeClaim); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Cluster Role Binding", giteaClusterRoleBinding.Name)
+		log.Infof("Created %s Persistent Volume Claim", persistentVolumeClaim.Name)
 	}
 
-	giteaOperator := kubernetes.NewAnsibleOperatorDeployment(workshop, r.Scheme, "gitea-operator", giteaNamespace.Name, labels, imageName+":"+imageTag, "gitea-operator")
-
-	if err := r.Create(context.TODO(), giteaOperator); err != nil && !errors.IsAlreadyExists(err) {
+	// Deploy/Update Gitea
+	dep := gitea.NewDeployment(workshop, r.Scheme, serviceName, giteaNamespace.Name, labels, adminSecret.Name)
+	if err := r.Create(context.TODO(), dep); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Operator", giteaOperator.Name)
+		log.Infof("Created %s Deployment", dep.Name)
+	} else if errors.IsAlreadyExists(err) {
+		deploymentFound := &appsv1.Deployment{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: dep.Name, Namespace: giteaNamespace.Name}, deploymentFound); err != nil {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			// Fields defaulted by the API server are ignored
+			if !equality.Semantic.DeepDerivative(dep.Spec.Template, deploymentFound.Spec.Template) {
+				// Update Gitea
+				deploymentFound.Spec.Template = dep.Spec.Template
+				if err := r.Update(context.TODO(), deploymentFound); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s Deployment", deploymentFound.Name)
+			}
+		}
 	}
 
-	giteaCustomResource := gitea.NewCustomResource(workshop, r.Scheme, "gitea-server", giteaNamespace.Name, labels)
-	if err := r.Create(context.TODO(), giteaCustomResource); err != nil && !errors.IsAlreadyExists(err) {
+	// Create Service
+	service := kubernetes.NewService(workshop, r.Scheme, serviceName, giteaNamespace.Name, labels, []string{"http"}, []int32{3000})
+	if err := r.Create(context.TODO(), service); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Custom Resource", giteaCustomResource.Name)
+		log.Infof("Created %s Service", service.Name)
 	}
 
-	// Wait for Server to be running
-	if !kubernetes.GetK8Client().GetDeploymentStatus("gitea-server", giteaNamespace.Name) {
-		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 1}, nil
+	// Expose Service
+	giteaHost, err := r.exposeService(workshop, serviceName, giteaNamespace.Name, labels, serviceName, 3000, true, appsHostnameSuffix)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
 
-	// extract app route suffix from openshift-console
-	giteaRouteFound := &routev1.Route{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: "gitea-server", Namespace: giteaNamespace.Name}, giteaRouteFound); err != nil {
-		log.Errorf("Failed to find %s route", "gitea-server")
-		return reconcile.Result{}, err
+	// Wait for Server to be running
+	if !kubernetes.GetK8Client().GetDeploymentStatus(serviceName, giteaNamespace.Name) {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 1}, nil
 	}
 
-	giteaURL := "https://" + giteaRouteFound.Spec.Host
+	giteaURL := "https://" + giteaHost
+	adminUsername := string(adminSecretFound.Data["username"])
+	adminPassword := string(adminSecretFound.Data["password"])
 
 	for id := 1; id <= users; id++ {
 		username := fmt.Sprintf("user%d", id)
 
-		if result, err := createGitUser(workshop, username, giteaURL); err != nil {
+		if result, err := createGitUser(workshop, username, giteaURL, adminUsername, adminPassword); err != nil {
 			return result, err
 		}
 	}
@@ -120,15 +159,16 @@ func (r *WorkshopReconciler) addGitea(workshop *workshopv1.Workshop, users int)
 	return reconcile.Result{}, nil
 }
 
-func createGitUser(workshop *workshopv1.Workshop, username string, giteaURL string) (reconcile.Result, error) {
+func createGitUser(workshop *workshopv1.Workshop, username string, giteaURL string,
+	adminUsername string, adminPassword string) (reconcile.Result, error) {
 
 	var (
 		openshiftUserPassword = workshop.Spec.User.Password
+		body                  []byte
 		err                   error
 		httpResponse          *http.Response
 		httpRequest           *http.Request
-		requestURL            = giteaURL + "/user/sign_up"
-		body                  = url.Values{}
+		requestURL            = giteaURL + "/api/v1/admin/users"
 		client                = &http.Client{
 			Transport: &http.Transport{
 				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
@@ -140,26 +180,32 @@ func createGitUser(workshop *workshopv1.Workshop, username string, giteaURL stri
 		}
 	)
 
-	body.Set("user_name", username)
-	body.Set("email", username+"@none.com")
-	body.Set("password", openshiftUserPassword)
-	body.Set("retype", openshiftUserPassword)
+	body, err = json.Marshal(gitea.NewUser(username, openshiftUserPassword))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
-	httpRequest, err = http.NewRequest("POST", requestURL, strings.NewReader(body.Encode()))
-	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpRequest, err = http.NewRequest("POST", requestURL, bytes.NewBuffer(body))
+	httpRequest.Header.Set("Authorization", "Basic "+util.GetBasicAuth(adminUsername, adminPassword))
+	httpRequest.Header.Set("Content-Type", "application/json")
 	httpRequest.Header.Set("Accept", "application/json")
-	httpRequest.Header.Set("Content-Length", strconv.Itoa(len(body.Encode())))
 
 	httpResponse, err = client.Do(httpRequest)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	if httpResponse.StatusCode == http.StatusCreated {
+	defer httpResponse.Body.Close()
+
+	switch httpResponse.StatusCode {
+	case http.StatusCreated:
 		log.Infof("Created %s user in Gitea", username)
+	case http.StatusUnprocessableEntity:
+		// User already exists
+	default:
+		log.Errorf("Error when creating %s user in Gitea (%d)", username, httpResponse.StatusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
 	}
 
-	defer httpResponse.Body.Close()
-
 	//Success
 	return reconcile.Result{}, nil
 }
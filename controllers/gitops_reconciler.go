@@ -2,8 +2,11 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"reflect"
+	"regexp"
+	"time"
 
 	argocdoperatorv1 "github.com/argoproj-labs/argocd-operator/pkg/apis/argoproj/v1alpha1"
 	argocdv1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
@@ -17,16 +20,30 @@ import (
 
 	"github.com/mcouliba/workshop-operator/common/util"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// argocdAccountRegexp matches the argocd-cm entries of the local user accounts
+var argocdAccountRegexp = regexp.MustCompile(`^accounts\.user[0-9]+$`)
+
+// argocdAccountKeyRegexp matches the argocd-secret keys of the local user accounts
+var argocdAccountKeyRegexp = regexp.MustCompile(`^accounts\.(user[0-9]+)\.`)
+
 // Reconciling GitOps
 func (r *WorkshopReconciler) reconcileGitOps(workshop *workshopv1.Workshop, users int,
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
 	enabledGitOps := workshop.Spec.Infrastructure.GitOps.Enabled
 
 	if enabledGitOps {
+		// The default instance and Dex with OpenShift OAuth are provided by OpenShift GitOps only
+		openshiftOnly := workshop.Spec.Infrastructure.GitOps.UseDefaultInstance || workshop.Spec.Infrastructure.GitOps.OpenShiftOAuth
+		if unsupported, err := r.isOpenShiftOnly(workshop, workshopv1.WorkshopGitOpsSupported,
+			"OpenShift GitOps with the default instance or OpenShift OAuth", openshiftOnly); unsupported || err != nil {
+			return reconcile.Result{}, err
+		}
+
 		if result, err := r.addGitOps(workshop, users, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
 			return result, err
 		}
@@ -40,7 +57,7 @@ func (r *WorkshopReconciler) addGitOps(workshop *workshopv1.Workshop, users int,
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
 
 	name := "openshift-gitops-operator"
-	operatorNamespace := "openshift-operators"
+	operatorNamespace := r.operatorsNamespace()
 	channel := workshop.Spec.Infrastructure.GitOps.OperatorHub.Channel
 	clusterServiceVersion := workshop.Spec.Infrastructure.GitOps.OperatorHub.ClusterServiceVersion
 
@@ -48,13 +65,25 @@ func (r *WorkshopReconciler) addGitOps(workshop *workshopv1.Workshop, users int,
 		"app.kubernetes.io/part-of": "argocd",
 	}
 
+	operatorDeploymentName := "gitops-operator"
 	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, name, operatorNamespace,
 		name, channel, clusterServiceVersion)
+	if r.Platform != kubernetes.OpenShift {
+		// OperatorHub.io ships the upstream Argo CD Operator
+		name = "argocd-operator"
+		operatorDeploymentName = "argocd-operator-controller-manager"
+		subscription = kubernetes.NewCommunitySubscription(workshop, r.Scheme, name, operatorNamespace,
+			name, channel, clusterServiceVersion)
+		r.useCatalog(subscription)
+	}
 	if err := r.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Subscription", subscription.Name)
 	}
+	if err := r.acquireShared(workshop, subscription); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// Approve the installation
 	if err := r.ApproveInstallPlan(clusterServiceVersion, name, operatorNamespace); err != nil {
@@ -63,130 +92,134 @@ func (r *WorkshopReconciler) addGitOps(workshop *workshopv1.Workshop, users int,
 	}
 
 	// Wait for Operator to be running
-	if !kubernetes.GetK8Client().GetDeploymentStatus("gitops-operator", operatorNamespace) {
+	if !kubernetes.GetK8Client().GetDeploymentStatus(operatorDeploymentName, operatorNamespace) {
 		return reconcile.Result{Requeue: true}, nil
 	}
 
-	namespace := kubernetes.NewNamespace(workshop, r.Scheme, "argocd")
-	if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
+	useDefaultInstance := workshop.Spec.Infrastructure.GitOps.UseDefaultInstance
+	instanceName, instanceNamespace := argocdInstance(workshop)
+
+	namespace := kubernetes.NewNamespace(workshop, r.Scheme, instanceNamespace)
+	if useDefaultInstance {
+		// The namespace is provisioned by OpenShift GitOps
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: namespace.Name}, &corev1.Namespace{}); err != nil {
+			if errors.IsNotFound(err) {
+				log.Infof("Waiting for OpenShift GitOps to create %s Namespace", namespace.Name)
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	} else if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Project", namespace.Name)
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(workshop.Spec.User.Password), bcrypt.DefaultCost)
-	if err != nil {
-		log.Errorf("Error when Bcrypt encrypt password for Argo CD: %v", err)
-		return reconcile.Result{}, err
-	}
-	bcryptPassword := string(hashedPassword)
+	openshiftOAuth := workshop.Spec.Infrastructure.GitOps.OpenShiftOAuth
 
 	argocdPolicy := ""
+	if openshiftOAuth {
+		for _, group := range workshop.Spec.Infrastructure.GitOps.AdminGroups {
+			argocdPolicy = fmt.Sprintf("%sg, %s, role:admin\n", argocdPolicy, group)
+		}
+	}
 	namespaceList := ""
-	secretData := map[string]string{}
+	accounts := []string{}
 	configMapData := map[string]string{}
 
 	for id := 1; id <= users; id++ {
 		username := fmt.Sprintf("user%d", id)
 		userRole := fmt.Sprintf("role:%s", username)
-		projectName := fmt.Sprintf("%s%d", workshop.Spec.Infrastructure.Project.StagingName, id)
-		if id == 1 {
-			namespaceList = projectName
-		} else {
-			namespaceList = fmt.Sprintf("%s,%s", namespaceList, projectName)
-		}
 
-		userPolicy := `p, ` + userRole + `, applications, *, ` + projectName + `/*, allow
-p, ` + userRole + `, clusters, get, https://kubernetes.default.svc, allow
-p, ` + userRole + `, projects, *,` + projectName + `, allow
-p, ` + userRole + `, repositories, *, http://gitea-server.gitea.svc:3000/` + username + `/*, allow
+		userPolicy := `p, ` + userRole + `, clusters, get, https://kubernetes.default.svc, allow
+p, ` + userRole + `, repositories, *, http://gitea-server.` + workshop.Status.Namespaces.Gitea + `.svc:3000/` + username + `/*, allow
 g, ` + username + `, ` + userRole + `
 `
 		argocdPolicy = fmt.Sprintf("%s%s", argocdPolicy, userPolicy)
 
-		secretData[fmt.Sprintf("accounts.%s.password", username)] = bcryptPassword
+		// With OpenShift OAuth, the users log in with their OpenShift account
+		if !openshiftOAuth {
+			accounts = append(accounts, username)
 
-		configMapData[fmt.Sprintf("accounts.%s", username)] = "login"
+			configMapData[fmt.Sprintf("accounts.%s", username)] = "login"
+		}
 
-		labels["app.kubernetes.io/name"] = "appproject-cr"
-		appProjectCustomResource := argocd.NewAppProjectCustomResource(workshop, r.Scheme, projectName, namespace.Name, labels, argocdPolicy)
-		if err := r.Create(context.TODO(), appProjectCustomResource); err != nil && !errors.IsAlreadyExists(err) {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Created %s Custom Resource", appProjectCustomResource.Name)
-		} else if errors.IsAlreadyExists(err) {
-			customResourceFound := &argocdv1.AppProject{}
-			if err := r.Get(context.TODO(), types.NamespacedName{Name: appProjectCustomResource.Name, Namespace: namespace.Name}, customResourceFound); err != nil {
+		for _, projectName := range userGitOpsProjectNames(workshop, id) {
+			if namespaceList == "" {
+				namespaceList = projectName
+			} else {
+				namespaceList = fmt.Sprintf("%s,%s", namespaceList, projectName)
+			}
+
+			projectPolicy := `p, ` + userRole + `, applications, *, ` + projectName + `/*, allow
+p, ` + userRole + `, projects, *,` + projectName + `, allow
+`
+			argocdPolicy = fmt.Sprintf("%s%s", argocdPolicy, projectPolicy)
+
+			labels["app.kubernetes.io/name"] = "appproject-cr"
+			appProjectCustomResource := argocd.NewAppProjectCustomResource(workshop, r.Scheme, projectName, namespace.Name, labels, argocdPolicy)
+			if err := r.Create(context.TODO(), appProjectCustomResource); err != nil && !errors.IsAlreadyExists(err) {
 				return reconcile.Result{}, err
 			} else if err == nil {
-				if !reflect.DeepEqual(appProjectCustomResource.Spec, customResourceFound.Spec) {
-					customResourceFound.Spec = appProjectCustomResource.Spec
-					if err := r.Update(context.TODO(), customResourceFound); err != nil {
-						return reconcile.Result{}, err
+				log.Infof("Created %s Custom Resource", appProjectCustomResource.Name)
+			} else if errors.IsAlreadyExists(err) {
+				customResourceFound := &argocdv1.AppProject{}
+				if err := r.Get(context.TODO(), types.NamespacedName{Name: appProjectCustomResource.Name, Namespace: namespace.Name}, customResourceFound); err != nil {
+					return reconcile.Result{}, err
+				} else if err == nil {
+					if !reflect.DeepEqual(appProjectCustomResource.Spec, customResourceFound.Spec) {
+						customResourceFound.Spec = appProjectCustomResource.Spec
+						if err := r.Update(context.TODO(), customResourceFound); err != nil {
+							return reconcile.Result{}, err
+						}
+						log.Infof("Updated %s Custom Resource", customResourceFound.Name)
 					}
-					log.Infof("Updated %s Custom Resource", customResourceFound.Name)
 				}
 			}
-		}
 
-		subjects := []rbac.Subject{}
-		argocdSubject := rbac.Subject{
-			Kind:     rbac.UserKind,
-			Name:     "system:serviceaccount:argocd:argocd-argocd-application-controller",
-			APIGroup: "rbac.authorization.k8s.io",
-		}
+			subjects := []rbac.Subject{}
+			argocdSubject := rbac.Subject{
+				Kind:     rbac.UserKind,
+				Name:     argocd.ApplicationControllerUser(instanceName, instanceNamespace),
+				APIGroup: "rbac.authorization.k8s.io",
+			}
 
-		subjects = append(subjects, argocdSubject)
+			subjects = append(subjects, argocdSubject)
 
-		role := kubernetes.NewRole(workshop, r.Scheme,
-			"argocd-manager", projectName, labels, kubernetes.ArgoCDRules())
-		if err := r.Create(context.TODO(), role); err != nil && !errors.IsAlreadyExists(err) {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Created %s Role in %s namespace", role.Name, projectName)
-		}
+			role := kubernetes.NewRole(workshop, r.Scheme,
+				"argocd-manager", projectName, labels, kubernetes.ArgoCDRules())
+			if err := r.Create(context.TODO(), role); err != nil && !errors.IsAlreadyExists(err) {
+				return reconcile.Result{}, err
+			} else if err == nil {
+				log.Infof("Created %s Role in %s namespace", role.Name, projectName)
+			}
 
-		roleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
-			"argocd-manager", projectName, labels, subjects, role.Name, "Role")
-		if err := r.Create(context.TODO(), roleBinding); err != nil && !errors.IsAlreadyExists(err) {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Created %s Role Binding in %s namespace", roleBinding.Name, projectName)
-		} else if errors.IsAlreadyExists(err) {
-			found := &rbac.RoleBinding{}
-			if err := r.Get(context.TODO(), types.NamespacedName{Name: roleBinding.Name, Namespace: projectName}, found); err != nil {
+			roleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
+				"argocd-manager", projectName, labels, subjects, role.Name, "Role")
+			if err := r.Create(context.TODO(), roleBinding); err != nil && !errors.IsAlreadyExists(err) {
 				return reconcile.Result{}, err
 			} else if err == nil {
-				if !reflect.DeepEqual(subjects, found.Subjects) {
-					found.Subjects = subjects
-					if err := r.Update(context.TODO(), found); err != nil {
-						return reconcile.Result{}, err
+				log.Infof("Created %s Role Binding in %s namespace", roleBinding.Name, projectName)
+			} else if errors.IsAlreadyExists(err) {
+				found := &rbac.RoleBinding{}
+				if err := r.Get(context.TODO(), types.NamespacedName{Name: roleBinding.Name, Namespace: projectName}, found); err != nil {
+					return reconcile.Result{}, err
+				} else if err == nil {
+					if !reflect.DeepEqual(subjects, found.Subjects) {
+						found.Subjects = subjects
+						if err := r.Update(context.TODO(), found); err != nil {
+							return reconcile.Result{}, err
+						}
+						log.Infof("Updated %s Role Binding in %s namespace", found.Name, projectName)
 					}
-					log.Infof("Updated %s Role Binding in %s namespace", found.Name, projectName)
 				}
 			}
 		}
 	}
 
 	labels["app.kubernetes.io/name"] = "argocd-secret"
-	secret := kubernetes.NewStringDataSecret(workshop, r.Scheme, "argocd-secret", namespace.Name, labels, secretData)
-	if err := r.Create(context.TODO(), secret); err != nil && !errors.IsAlreadyExists(err) {
-		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Secret", secret.Name)
-		// } else if errors.IsAlreadyExists(err) {
-		// 	secretFound := &corev1.Secret{}
-		// 	if err := r.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: namespace.Name}, secretFound); err != nil {
-		// 		return reconcile.Result{}, err
-		// 	} else if err == nil {
-		// 		if !util.IsIntersectMap(secretData, secretFound.StringData) {
-		// 			secretFound.StringData = secretData
-		// 			if err := r.Update(context.TODO(), secretFound); err != nil {
-		// 				return reconcile.Result{}, err
-		// 			}
-		// 			log.Infof("Updated %s Secret", secretFound.Name)
-		// 		}
-		// 	}
+	if result, err := r.manageArgocdSecret(workshop, namespace.Name, labels, accounts); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	labels["app.kubernetes.io/name"] = "argocd-cm"
@@ -200,8 +233,22 @@ g, ` + username + `, ` + userRole + `
 		if err := r.Get(context.TODO(), types.NamespacedName{Name: configmap.Name, Namespace: namespace.Name}, configmapFound); err != nil {
 			return reconcile.Result{}, err
 		} else if err == nil {
-			if !util.IsIntersectMap(configMapData, configmapFound.Data) {
-				configmapFound.Data = configMapData
+			// Keep the settings managed by the Argo CD Operator
+			// and disable the local accounts which no longer exist
+			changed := !util.IsIntersectMap(configMapData, configmapFound.Data)
+			for key := range configmapFound.Data {
+				if _, managed := configMapData[key]; !managed && argocdAccountRegexp.MatchString(key) {
+					delete(configmapFound.Data, key)
+					changed = true
+				}
+			}
+			if changed {
+				if configmapFound.Data == nil {
+					configmapFound.Data = map[string]string{}
+				}
+				for key, value := range configMapData {
+					configmapFound.Data[key] = value
+				}
 				if err := r.Update(context.TODO(), configmapFound); err != nil {
 					return reconcile.Result{}, err
 				}
@@ -211,8 +258,18 @@ g, ` + username + `, ` + userRole + `
 	}
 
 	labels["app.kubernetes.io/name"] = "argocd-cr"
-	argoCDCustomResource := argocd.NewArgoCDCustomResource(workshop, r.Scheme, "argocd", namespace.Name, labels, argocdPolicy)
-	if err := r.Create(context.TODO(), argoCDCustomResource); err != nil && !errors.IsAlreadyExists(err) {
+	argoCDCustomResource := argocd.NewArgoCDCustomResource(workshop, r.Scheme, instanceName, namespace.Name, labels, argocdPolicy, openshiftOAuth)
+	if r.Platform != kubernetes.OpenShift {
+		// The server is exposed with an Ingress instead of a Route
+		argoCDCustomResource.Spec.Server.Route.Enabled = false
+		argoCDCustomResource.Spec.Server.Ingress.Enabled = true
+		argoCDCustomResource.Spec.Server.Host = fmt.Sprintf("%s-server-%s.%s", instanceName, namespace.Name, appsHostnameSuffix)
+	}
+	if useDefaultInstance {
+		if result, err := r.manageDefaultArgoCD(workshop, argoCDCustomResource); util.IsRequeued(result, err) {
+			return result, err
+		}
+	} else if err := r.Create(context.TODO(), argoCDCustomResource); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Custom Resource", argoCDCustomResource.Name)
@@ -221,8 +278,10 @@ g, ` + username + `, ` + userRole + `
 		if err := r.Get(context.TODO(), types.NamespacedName{Name: argoCDCustomResource.Name, Namespace: namespace.Name}, customResourceFound); err != nil {
 			return reconcile.Result{}, err
 		} else if err == nil {
-			if !reflect.DeepEqual(&argocdPolicy, customResourceFound.Spec.RBAC.Policy) {
-				customResourceFound.Spec.RBAC.Policy = &argocdPolicy
+			if !reflect.DeepEqual(argoCDCustomResource.Spec.RBAC, customResourceFound.Spec.RBAC) ||
+				argoCDCustomResource.Spec.Dex.OpenShiftOAuth != customResourceFound.Spec.Dex.OpenShiftOAuth {
+				customResourceFound.Spec.RBAC = argoCDCustomResource.Spec.RBAC
+				customResourceFound.Spec.Dex.OpenShiftOAuth = argoCDCustomResource.Spec.Dex.OpenShiftOAuth
 				if err := r.Update(context.TODO(), customResourceFound); err != nil {
 					return reconcile.Result{}, err
 				}
@@ -232,19 +291,247 @@ g, ` + username + `, ` + userRole + `
 	}
 
 	// Wait for ArgoCD Dex Server to be running
-	// if !kubernetes.GetK8Client().GetDeploymentStatus("argocd-dex-server", namespace.Name) {
-	// 	return reconcile.Result{Requeue: true}, nil
-	// }
+	if openshiftOAuth && !kubernetes.GetK8Client().GetDeploymentStatus(instanceName+"-dex-server", namespace.Name) {
+		return reconcile.Result{Requeue: true}, nil
+	}
 
 	// Wait for ArgoCD Server to be running
-	if !kubernetes.GetK8Client().GetDeploymentStatus("argocd-server", namespace.Name) {
+	if !kubernetes.GetK8Client().GetDeploymentStatus(instanceName+"-server", namespace.Name) {
 		return reconcile.Result{Requeue: true}, nil
 	}
 
-	labels["app.kubernetes.io/name"] = "argocd-default-cluster-config"
+	// The default instance manages the whole cluster and must not be restricted to the user projects
+	if !useDefaultInstance {
+		labels["app.kubernetes.io/name"] = "argocd-default-cluster-config"
 
-	if result, err := r.manageArgocdDefaultClusterConfigSecret(workshop, namespace.Name, labels, namespaceList); util.IsRequeued(result, err) {
-		return result, err
+		if result, err := r.manageArgocdDefaultClusterConfigSecret(workshop, namespace.Name, instanceName, labels, namespaceList); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	application := workshop.Spec.Infrastructure.GitOps.Application
+	if application.Enabled && application.Repository != "" {
+		labels["app.kubernetes.io/name"] = "application-cr"
+		for id := 1; id <= users; id++ {
+			username := fmt.Sprintf("user%d", id)
+			repoURL := fmt.Sprintf("http://gitea-server.%s.svc:3000/%s/%s",
+				workshop.Status.Namespaces.Gitea, username, application.Repository)
+
+			for _, projectName := range userGitOpsProjectNames(workshop, id) {
+				applicationCustomResource := argocd.NewApplicationCustomResource(workshop, r.Scheme, projectName, namespace.Name, labels,
+					projectName, repoURL, application.Path, application.TargetRevision, projectName, application.AutomatedSync)
+				if result, err := r.addArgocdApplication(applicationCustomResource); util.IsRequeued(result, err) {
+					return result, err
+				}
+			}
+		}
+	}
+
+	appOfApps := workshop.Spec.Infrastructure.GitOps.AppOfApps
+	if appOfApps.Enabled && appOfApps.RepositoryURL != "" {
+		labels["app.kubernetes.io/name"] = "app-of-apps-cr"
+		applicationCustomResource := argocd.NewApplicationCustomResource(workshop, r.Scheme, "app-of-apps", namespace.Name, labels,
+			"default", appOfApps.RepositoryURL, appOfApps.Path, appOfApps.TargetRevision, namespace.Name, true)
+		if result, err := r.addArgocdApplication(applicationCustomResource); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func (r *WorkshopReconciler) addArgocdApplication(applicationCustomResource *argocdv1.Application) (reconcile.Result, error) {
+
+	if err := r.Create(context.TODO(), applicationCustomResource); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Application", applicationCustomResource.Name)
+	} else if errors.IsAlreadyExists(err) {
+		customResourceFound := &argocdv1.Application{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: applicationCustomResource.Name, Namespace: applicationCustomResource.Namespace}, customResourceFound); err != nil {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			if !reflect.DeepEqual(applicationCustomResource.Spec, customResourceFound.Spec) {
+				customResourceFound.Spec = applicationCustomResource.Spec
+				if err := r.Update(context.TODO(), customResourceFound); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s Application", customResourceFound.Name)
+			}
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// argocdInstance returns the name and the namespace of the Argo CD instance used by the Workshop
+func argocdInstance(workshop *workshopv1.Workshop) (string, string) {
+	if workshop.Spec.Infrastructure.GitOps.UseDefaultInstance {
+		return "openshift-gitops", "openshift-gitops"
+	}
+	return "argocd", componentNamespace(workshop, workshop.Spec.Infrastructure.GitOps.Namespace, "argocd")
+}
+
+// manageDefaultArgoCD adds the Workshop RBAC policy to the instance provisioned by OpenShift GitOps
+func (r *WorkshopReconciler) manageDefaultArgoCD(workshop *workshopv1.Workshop,
+	argoCDCustomResource *argocdoperatorv1.ArgoCD) (reconcile.Result, error) {
+
+	customResourceFound := &argocdoperatorv1.ArgoCD{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: argoCDCustomResource.Name, Namespace: argoCDCustomResource.Namespace}, customResourceFound); err != nil {
+		if errors.IsNotFound(err) {
+			log.Infof("Waiting for OpenShift GitOps to create %s Custom Resource", argoCDCustomResource.Name)
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	policy := ""
+	if customResourceFound.Spec.RBAC.Policy != nil {
+		policy = *customResourceFound.Spec.RBAC.Policy
+	}
+	policy = argocd.MergePolicy(policy, *argoCDCustomResource.Spec.RBAC.Policy,
+		kubernetes.PrefixedName(workshop, "workshop-operator"))
+
+	if !reflect.DeepEqual(&policy, customResourceFound.Spec.RBAC.Policy) ||
+		!reflect.DeepEqual(argoCDCustomResource.Spec.RBAC.Scopes, customResourceFound.Spec.RBAC.Scopes) ||
+		(argoCDCustomResource.Spec.Dex.OpenShiftOAuth && !customResourceFound.Spec.Dex.OpenShiftOAuth) {
+		customResourceFound.Spec.RBAC.Policy = &policy
+		customResourceFound.Spec.RBAC.Scopes = argoCDCustomResource.Spec.RBAC.Scopes
+		if argoCDCustomResource.Spec.Dex.OpenShiftOAuth {
+			customResourceFound.Spec.Dex.OpenShiftOAuth = true
+		}
+		if err := r.Update(context.TODO(), customResourceFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Updated %s Custom Resource", customResourceFound.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// releaseDefaultArgoCD removes the Workshop RBAC policy from the instance provisioned by OpenShift GitOps
+func (r *WorkshopReconciler) releaseDefaultArgoCD(workshop *workshopv1.Workshop) error {
+	if !workshop.Spec.Infrastructure.GitOps.Enabled || !workshop.Spec.Infrastructure.GitOps.UseDefaultInstance {
+		return nil
+	}
+
+	instanceName, instanceNamespace := argocdInstance(workshop)
+	customResourceFound := &argocdoperatorv1.ArgoCD{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: instanceName, Namespace: instanceNamespace}, customResourceFound); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	if customResourceFound.Spec.RBAC.Policy == nil {
+		return nil
+	}
+
+	policy := argocd.MergePolicy(*customResourceFound.Spec.RBAC.Policy, "",
+		kubernetes.PrefixedName(workshop, "workshop-operator"))
+	if policy == *customResourceFound.Spec.RBAC.Policy {
+		return nil
+	}
+
+	customResourceFound.Spec.RBAC.Policy = &policy
+	if err := r.Update(context.TODO(), customResourceFound); err != nil {
+		return err
+	}
+	log.Infof("Removed the Workshop policy from %s Custom Resource", customResourceFound.Name)
+
+	return nil
+}
+
+// manageArgocdSecret sets the password of the local accounts in argocd-secret.
+// Hashes are only regenerated for new accounts or when the password changed,
+// and the keys of the accounts which no longer exist are removed
+func (r *WorkshopReconciler) manageArgocdSecret(workshop *workshopv1.Workshop, namespaceName string,
+	labels map[string]string, accounts []string) (reconcile.Result, error) {
+
+	password := workshop.Spec.User.Password
+	passwordHash := fmt.Sprintf("%x", sha256.Sum256([]byte(password)))
+
+	secretFound := &corev1.Secret{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "argocd-secret", Namespace: namespaceName}, secretFound); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	} else if errors.IsNotFound(err) {
+		secretFound = nil
+	}
+
+	passwordChanged := secretFound == nil || secretFound.Annotations[kubernetes.PasswordHashAnnotation] != passwordHash
+	managedAccounts := map[string]bool{}
+	staleAccounts := []string{}
+	for _, account := range accounts {
+		managedAccounts[account] = true
+		if passwordChanged {
+			staleAccounts = append(staleAccounts, account)
+		} else if _, found := secretFound.Data[fmt.Sprintf("accounts.%s.password", account)]; !found {
+			staleAccounts = append(staleAccounts, account)
+		}
+	}
+
+	removedKeys := []string{}
+	if secretFound != nil {
+		for key := range secretFound.Data {
+			if match := argocdAccountKeyRegexp.FindStringSubmatch(key); match != nil && !managedAccounts[match[1]] {
+				removedKeys = append(removedKeys, key)
+			}
+		}
+	}
+
+	if secretFound != nil && len(staleAccounts) == 0 && len(removedKeys) == 0 &&
+		secretFound.Annotations[kubernetes.PasswordHashAnnotation] == passwordHash {
+		//Success
+		return reconcile.Result{}, nil
+	}
+
+	secretData := map[string]string{}
+	if len(staleAccounts) > 0 {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			log.Errorf("Error when Bcrypt encrypt password for Argo CD: %v", err)
+			return reconcile.Result{}, err
+		}
+
+		// Argo CD revokes the tokens issued before the password modification time
+		passwordMtime := time.Now().UTC().Format(time.RFC3339)
+		for _, account := range staleAccounts {
+			secretData[fmt.Sprintf("accounts.%s.password", account)] = string(hashedPassword)
+			secretData[fmt.Sprintf("accounts.%s.passwordMtime", account)] = passwordMtime
+		}
+	}
+
+	if secretFound == nil {
+		secret := kubernetes.NewStringDataSecret(workshop, r.Scheme, "argocd-secret", namespaceName, labels, secretData)
+		secret.Annotations = map[string]string{
+			kubernetes.PasswordHashAnnotation: passwordHash,
+		}
+		if err := r.Create(context.TODO(), secret); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Created %s Secret", secret.Name)
+	} else {
+		// Keep the keys managed by the Argo CD Operator
+		if secretFound.Data == nil {
+			secretFound.Data = map[string][]byte{}
+		}
+		for key, value := range secretData {
+			secretFound.Data[key] = []byte(value)
+		}
+		for _, key := range removedKeys {
+			delete(secretFound.Data, key)
+		}
+		if secretFound.Annotations == nil {
+			secretFound.Annotations = map[string]string{}
+		}
+		secretFound.Annotations[kubernetes.PasswordHashAnnotation] = passwordHash
+		if err := r.Update(context.TODO(), secretFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Updated %s Secret for %d account(s)", secretFound.Name, len(staleAccounts))
 	}
 
 	//Success
@@ -252,9 +539,9 @@ g, ` + username + `, ` + userRole + `
 }
 
 func (r *WorkshopReconciler) manageArgocdDefaultClusterConfigSecret(workshop *workshopv1.Workshop, namespaceName string,
-	labels map[string]string, namespaceList string) (reconcile.Result, error) {
+	instanceName string, labels map[string]string, namespaceList string) (reconcile.Result, error) {
 
-	secretName := "argocd-default-cluster-config"
+	secretName := instanceName + "-default-cluster-config"
 	clusterConfigSecretData := map[string]string{}
 
 	clusterConfigSecretData["config"] = "{\"tlsClientConfig\":{\"insecure\":false}}"
@@ -272,7 +559,11 @@ func (r *WorkshopReconciler) manageArgocdDefaultClusterConfigSecret(workshop *wo
 		if err := r.Get(context.TODO(), types.NamespacedName{Name: clusterConfigSecret.Name, Namespace: namespaceName}, clusterConfigSecretFound); err != nil {
 			return reconcile.Result{}, err
 		} else if err == nil {
-			if !util.IsIntersectMap(clusterConfigSecretData, clusterConfigSecretFound.StringData) {
+			foundData := map[string]string{}
+			for key, value := range clusterConfigSecretFound.Data {
+				foundData[key] = string(value)
+			}
+			if !util.IsIntersectMap(clusterConfigSecretData, foundData) {
 				clusterConfigSecretFound.StringData = clusterConfigSecretData
 				if err := r.Update(context.TODO(), clusterConfigSecretFound); err != nil {
 					return reconcile.Result{}, err
@@ -285,3 +576,14 @@ func (r *WorkshopReconciler) manageArgocdDefaultClusterConfigSecret(workshop *wo
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// userGitOpsProjectNames returns the names of the projects of the user managed by Argo CD
+func userGitOpsProjectNames(workshop *workshopv1.Workshop, id int) []string {
+	names := []string{}
+	for _, project := range userProjects(workshop, id) {
+		if project.template.GitOps {
+			names = append(names, project.name)
+		}
+	}
+	return names
+}
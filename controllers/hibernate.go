@@ -0,0 +1,262 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/kubernetes"
+	"github.com/mcouliba/workshop-operator/common/util"
+	"github.com/prometheus/common/log"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// hibernationTier groups the workloads scaled down together
+type hibernationTier struct {
+	namespaces []string
+	// owned restricts the tier to the workloads controlled by the Workshop
+	owned bool
+	// workspaces stops the CodeReady Workspaces of the users before scaling down
+	workspaces bool
+}
+
+// workload is a Deployment or a StatefulSet
+type workload struct {
+	object   runtime.Object
+	meta     metav1.Object
+	kind     string
+	replicas **int32
+	current  int32
+}
+
+// hibernateWorkshop stops the workspaces and scales the workloads of the Workshop to zero,
+// from the ones the users work with to the ones they depend on
+func (r *WorkshopReconciler) hibernateWorkshop(workshop *workshopv1.Workshop, users int,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+
+	for _, tier := range hibernationTiers(workshop, users) {
+		if tier.workspaces {
+			if result, err := r.stopCodeReadyWorkspaces(workshop, users, appsHostnameSuffix); util.IsRequeued(result, err) {
+				return result, err
+			}
+		}
+
+		workloads, err := r.listWorkloads(workshop, tier)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		stopping := false
+		for _, workload := range workloads {
+			if hibernateReplicas(workload.meta, workload.replicas) {
+				if err := r.Update(context.TODO(), workload.object); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Scaled down %s %s in %s", workload.meta.GetName(), workload.kind, workload.meta.GetNamespace())
+			}
+			stopping = stopping || workload.current > 0
+		}
+
+		// Wait for the tier to be stopped before scaling down what it depends on
+		if stopping {
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+	}
+
+	if err := r.updateCondition(workshop, workshopv1.WorkshopHibernated, corev1.ConditionTrue, "Hibernated",
+		"Workloads are scaled to zero"); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// resumeWorkshop restores the replicas of the workloads in the reverse order of the hibernation
+func (r *WorkshopReconciler) resumeWorkshop(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
+
+	if !isHibernated(workshop) {
+		return reconcile.Result{}, nil
+	}
+
+	tiers := hibernationTiers(workshop, users)
+	for i := len(tiers) - 1; i >= 0; i-- {
+		workloads, err := r.listWorkloads(workshop, tiers[i])
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		for j := len(workloads) - 1; j >= 0; j-- {
+			workload := workloads[j]
+			if !restoreReplicas(workload.meta, workload.replicas) {
+				continue
+			}
+			if err := r.Update(context.TODO(), workload.object); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Scaled up %s %s in %s", workload.meta.GetName(), workload.kind, workload.meta.GetNamespace())
+		}
+	}
+
+	if err := r.updateCondition(workshop, workshopv1.WorkshopHibernated, corev1.ConditionFalse, "Resumed",
+		"Workloads are restored to their replicas"); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// stopCodeReadyWorkspaces stops the workspaces of the users while the CodeReady server is still running
+func (r *WorkshopReconciler) stopCodeReadyWorkspaces(workshop *workshopv1.Workshop, users int,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+
+	namespace := workshop.Status.Namespaces.CodeReadyWorkspace
+
+	deploymentFound := &appsv1.Deployment{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "codeready", Namespace: namespace}, deploymentFound); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if deploymentFound.Status.ReadyReplicas == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	return stopWorkspaces(workshop, users, "codeready", namespace, appsHostnameSuffix)
+}
+
+// hibernationTiers returns the tiers of workloads in scale down order
+func hibernationTiers(workshop *workshopv1.Workshop, users int) []hibernationTier {
+	namespaces := workshop.Status.Namespaces
+	tiers := []hibernationTier{}
+
+	if workshop.Spec.Hibernate.UserWorkloads && workshop.Spec.Infrastructure.Project.Enabled {
+		projects := []string{}
+		for id := 1; id <= users; id++ {
+			projects = append(projects, userProjectNames(workshop, id)...)
+		}
+		tiers = append(tiers, hibernationTier{namespaces: projects})
+	}
+
+	// Bookbag and the portal
+	tiers = append(tiers, hibernationTier{namespaces: []string{namespaces.Guide, workshop.Namespace}, owned: true})
+
+	// CodeReady Workspaces is deployed by its operator, which is scaled down with it
+	if workshop.Spec.Infrastructure.CodeReadyWorkspace.Enabled {
+		tiers = append(tiers, hibernationTier{namespaces: []string{namespaces.CodeReadyWorkspace}, workspaces: true})
+	}
+
+	tiers = append(tiers,
+		hibernationTier{namespaces: []string{namespaces.Gitea, namespaces.Nexus}, owned: true},
+		hibernationTier{namespaces: []string{namespaces.Vault}, owned: true},
+	)
+
+	return tiers
+}
+
+// listWorkloads returns the Deployments and StatefulSets of a tier, operators first
+// so that they do not scale their operands back up
+func (r *WorkshopReconciler) listWorkloads(workshop *workshopv1.Workshop, tier hibernationTier) ([]workload, error) {
+	workloads := []workload{}
+
+	for _, namespace := range tier.namespaces {
+		if namespace == "" {
+			continue
+		}
+
+		deployments := &appsv1.DeploymentList{}
+		if err := r.List(context.TODO(), deployments, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for i := range deployments.Items {
+			deployment := &deployments.Items[i]
+			if tier.owned && !metav1.IsControlledBy(deployment, workshop) {
+				continue
+			}
+			workloads = append(workloads, workload{object: deployment, meta: deployment, kind: "Deployment",
+				replicas: &deployment.Spec.Replicas, current: deployment.Status.Replicas})
+		}
+
+		statefulSets := &appsv1.StatefulSetList{}
+		if err := r.List(context.TODO(), statefulSets, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for i := range statefulSets.Items {
+			statefulSet := &statefulSets.Items[i]
+			if tier.owned && !metav1.IsControlledBy(statefulSet, workshop) {
+				continue
+			}
+			workloads = append(workloads, workload{object: statefulSet, meta: statefulSet, kind: "StatefulSet",
+				replicas: &statefulSet.Spec.Replicas, current: statefulSet.Status.Replicas})
+		}
+	}
+
+	sort.SliceStable(workloads, func(i, j int) bool {
+		return strings.HasSuffix(workloads[i].meta.GetName(), "-operator") &&
+			!strings.HasSuffix(workloads[j].meta.GetName(), "-operator")
+	})
+
+	return workloads, nil
+}
+
+// hibernateReplicas records the replicas of the workload in an annotation and sets them to zero.
+// It returns false if the workload is already scaled down.
+func hibernateReplicas(objMeta metav1.Object, replicas **int32) bool {
+	if *replicas != nil && **replicas == 0 {
+		return false
+	}
+
+	original := int32(1)
+	if *replicas != nil {
+		original = **replicas
+	}
+
+	annotations := objMeta.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[kubernetes.ReplicasAnnotation] = strconv.Itoa(int(original))
+	objMeta.SetAnnotations(annotations)
+
+	zero := int32(0)
+	*replicas = &zero
+	return true
+}
+
+// restoreReplicas sets the replicas of the workload back from its annotation.
+// It returns false if the workload was not scaled down by the operator.
+func restoreReplicas(objMeta metav1.Object, replicas **int32) bool {
+	annotations := objMeta.GetAnnotations()
+	value, ok := annotations[kubernetes.ReplicasAnnotation]
+	if !ok {
+		return false
+	}
+
+	original, err := strconv.Atoi(value)
+	if err != nil {
+		original = 1
+	}
+	delete(annotations, kubernetes.ReplicasAnnotation)
+	objMeta.SetAnnotations(annotations)
+
+	restored := int32(original)
+	*replicas = &restored
+	return true
+}
+
+// isHibernated returns true if the workloads of the Workshop are scaled to zero
+func isHibernated(workshop *workshopv1.Workshop) bool {
+	return isConditionTrue(workshop, workshopv1.WorkshopHibernated)
+}
@@ -10,9 +10,9 @@ import (
 	securityv1 "github.com/openshift/api/security/v1"
 	"github.com/prometheus/common/log"
 
+	corev1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -36,15 +36,19 @@ func (r *WorkshopReconciler) addIstioWorkspace(workshop *workshopv1.Workshop, us
 	channel := workshop.Spec.Infrastructure.IstioWorkspace.OperatorHub.Channel
 	clusterserviceversion := workshop.Spec.Infrastructure.IstioWorkspace.OperatorHub.ClusterServiceVersion
 
-	subscription := kubernetes.NewCommunitySubscription(workshop, r.Scheme, "istio-workspace-operator", "openshift-operators",
+	subscription := kubernetes.NewCommunitySubscription(workshop, r.Scheme, "istio-workspace-operator", r.operatorsNamespace(),
 		"istio-workspace-operator", channel, clusterserviceversion)
+	r.useCatalog(subscription)
 	if err := r.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Subscription", subscription.Name)
 	}
+	if err := r.acquireShared(workshop, subscription); err != nil {
+		return reconcile.Result{}, err
+	}
 
-	if err := r.ApproveInstallPlan(clusterserviceversion, "istio-workspace-operator", "openshift-operators"); err != nil {
+	if err := r.ApproveInstallPlan(clusterserviceversion, "istio-workspace-operator", r.operatorsNamespace()); err != nil {
 		log.Infof("Waiting for Subscription to create InstallPlan for %s", subscription.Name)
 		return reconcile.Result{Requeue: true}, nil
 	}
@@ -53,61 +57,44 @@ func (r *WorkshopReconciler) addIstioWorkspace(workshop *workshopv1.Workshop, us
 		"app.kubernetes.io/part-of": "istio-workspace",
 	}
 
+	sccLabels := map[string]string{
+		"app.kubernetes.io/part-of": "istio-workspace",
+		kubernetes.WorkshopLabel:    kubernetes.WorkshopLabelValue(workshop),
+	}
+
 	for id := 1; id <= users; id++ {
 		username := fmt.Sprintf("user%d", id)
-		stagingProjectName := fmt.Sprintf("%s%d", workshop.Spec.Infrastructure.Project.StagingName, id)
-
-		role := kubernetes.NewRole(workshop, r.Scheme,
-			username+"-istio-workspace", stagingProjectName, labels, kubernetes.IstioWorkspaceUserRules())
-		if err := r.Create(context.TODO(), role); err != nil && !errors.IsAlreadyExists(err) {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Created %s Role", role.Name)
-		}
-
-		users := []rbac.Subject{
-			{
-				Kind: rbac.UserKind,
-				Name: username,
-			},
-		}
-
-		roleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
-			username+"-istio-workspace", stagingProjectName, labels, users, username+"-istio-workspace", "Role")
-		if err := r.Create(context.TODO(), roleBinding); err != nil && !errors.IsAlreadyExists(err) {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Created %s Role Binding", roleBinding.Name)
-		}
-
-		// Create SCC
-		serviceAccountUser := "system:serviceaccount:" + stagingProjectName + ":default"
 
-		privilegedSCCFound := &securityv1.SecurityContextConstraints{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: "privileged"}, privilegedSCCFound); err != nil {
-			return reconcile.Result{}, err
-		}
-
-		if !util.StringInSlice(serviceAccountUser, privilegedSCCFound.Users) {
-			privilegedSCCFound.Users = append(privilegedSCCFound.Users, serviceAccountUser)
-			if err := r.Update(context.TODO(), privilegedSCCFound); err != nil {
+		for _, projectName := range userProjectNames(workshop, id) {
+			role := kubernetes.NewRole(workshop, r.Scheme,
+				username+"-istio-workspace", projectName, labels, kubernetes.IstioWorkspaceUserRules())
+			if err := r.Create(context.TODO(), role); err != nil && !errors.IsAlreadyExists(err) {
 				return reconcile.Result{}, err
 			} else if err == nil {
-				log.Infof("Updated %s SCC", privilegedSCCFound.Name)
+				log.Infof("Created %s Role", role.Name)
 			}
-		}
 
-		anyuidSCCFound := &securityv1.SecurityContextConstraints{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: "anyuid"}, anyuidSCCFound); err != nil {
-			return reconcile.Result{}, err
-		}
+			users := []rbac.Subject{
+				{
+					Kind: rbac.UserKind,
+					Name: username,
+				},
+			}
 
-		if !util.StringInSlice(serviceAccountUser, anyuidSCCFound.Users) {
-			anyuidSCCFound.Users = append(anyuidSCCFound.Users, serviceAccountUser)
-			if err := r.Update(context.TODO(), anyuidSCCFound); err != nil {
+			roleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
+				username+"-istio-workspace", projectName, labels, users, username+"-istio-workspace", "Role")
+			if err := r.Create(context.TODO(), roleBinding); err != nil && !errors.IsAlreadyExists(err) {
 				return reconcile.Result{}, err
 			} else if err == nil {
-				log.Infof("Updated %s SCC", anyuidSCCFound.Name)
+				log.Infof("Created %s Role Binding", roleBinding.Name)
+			}
+
+			// Telepresence swaps the deployments with a proxy running as root and managing the network
+			scc := kubernetes.NewSecurityContextConstraints(workshop, r.Scheme,
+				kubernetes.PrefixedName(workshop, "workshop-istio-workspace"), sccLabels, securityv1.RunAsUserStrategyRunAsAny,
+				[]corev1.Capability{"NET_ADMIN", "NET_RAW"}, true)
+			if result, err := r.addSecurityContextConstraints(workshop, scc, projectName, "default"); util.IsRequeued(result, err) {
+				return result, err
 			}
 		}
 	}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/kubernetes"
+)
+
+// resolveNamespaces returns the namespaces of the components, set in the spec or defaulted
+func (r *WorkshopReconciler) resolveNamespaces(workshop *workshopv1.Workshop) workshopv1.NamespacesStatus {
+	infrastructure := workshop.Spec.Infrastructure
+
+	certManagerNamespace := infrastructure.CertManager.Namespace
+	if certManagerNamespace == "" {
+		certManagerNamespace = "cert-manager"
+	}
+	// OperatorHub.io ships cert-manager itself, running in the operators namespace
+	if r.Platform != kubernetes.OpenShift {
+		certManagerNamespace = r.operatorsNamespace()
+	}
+
+	serverlessNamespace := infrastructure.Serverless.Namespace
+	if serverlessNamespace == "" {
+		serverlessNamespace = "openshift-serverless"
+	}
+
+	serviceMeshNamespace := infrastructure.ServiceMesh.Namespace
+	if serviceMeshNamespace == "" {
+		serviceMeshNamespace = "istio-system"
+	}
+
+	_, gitOpsNamespace := argocdInstance(workshop)
+
+	return workshopv1.NamespacesStatus{
+		CertManager:        certManagerNamespace,
+		CodeReadyWorkspace: componentNamespace(workshop, infrastructure.CodeReadyWorkspace.Namespace, "workspaces"),
+		Gitea:              componentNamespace(workshop, infrastructure.Gitea.Namespace, "gitea"),
+		GitOps:             gitOpsNamespace,
+		Guide:              componentNamespace(workshop, infrastructure.Guide.Namespace, "workshop-guides"),
+		KnativeServing:     "knative-serving",
+		Nexus:              componentNamespace(workshop, infrastructure.Nexus.Namespace, "opentlc-shared"),
+		Serverless:         serverlessNamespace,
+		ServiceMesh:        serviceMeshNamespace,
+		Vault:              componentNamespace(workshop, infrastructure.Vault.Namespace, "vault"),
+	}
+}
+
+// componentNamespace returns the namespace set in the spec, or the default one prefixed for the Workshop
+func componentNamespace(workshop *workshopv1.Workshop, namespace string, defaultName string) string {
+	if namespace != "" {
+		return namespace
+	}
+	return kubernetes.PrefixedName(workshop, defaultName)
+}
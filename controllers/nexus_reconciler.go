@@ -1,7 +1,16 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"time"
 
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
 	"github.com/mcouliba/workshop-operator/common/kubernetes"
@@ -9,81 +18,483 @@ import (
 	"github.com/prometheus/common/log"
 
 	"github.com/mcouliba/workshop-operator/common/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// nexusDefaultAdminPassword is the admin password of a fresh Nexus instance
+const nexusDefaultAdminPassword = "admin123"
+
 // Reconciling Nexus
-func (r *WorkshopReconciler) reconcileNexus(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) reconcileNexus(workshop *workshopv1.Workshop, users int,
+	appsHostnameSuffix string) (reconcile.Result, error) {
 	enabledNexus := workshop.Spec.Infrastructure.Nexus.Enabled
 
 	if enabledNexus {
+		if err := r.updateComponentStatus(workshop, &workshop.Status.Nexus, util.OperatorStatus.InProgress); err != nil {
+			return reconcile.Result{}, err
+		}
 
-		if result, err := r.addNexus(workshop); util.IsRequeued(result, err) {
+		if result, err := r.addNexus(workshop, users, appsHostnameSuffix); util.IsRequeued(result, err) {
 			return result, err
 		}
 
+		if err := r.updateComponentStatus(workshop, &workshop.Status.Nexus, util.OperatorStatus.Installed); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else {
+		if err := r.updateComponentStatus(workshop, &workshop.Status.Nexus, util.OperatorStatus.NotScheduled); err != nil {
+			return reconcile.Result{}, err
+		}
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) addNexus(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addNexus(workshop *workshopv1.Workshop, users int,
+	appsHostnameSuffix string) (reconcile.Result, error) {
 
+	serviceName := "nexus"
 	labels := map[string]string{
+		"app":                       serviceName,
 		"app.kubernetes.io/part-of": "nexus",
 	}
 
-	nexusNamespace := kubernetes.NewNamespace(workshop, r.Scheme, "opentlc-shared")
+	nexusNamespace := kubernetes.NewNamespace(workshop, r.Scheme, workshop.Status.Namespaces.Nexus)
 	if err := r.Create(context.TODO(), nexusNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Project", nexusNamespace.Name)
 	}
 
-	nexusCustomResourceDefinition := kubernetes.NewCustomResourceDefinition(workshop, r.Scheme, "nexus.gpte.opentlc.com", "gpte.opentlc.com", "Nexus", "NexusList", "nexus", "nexus", "v1alpha1", nil, nil)
-	if err := r.Create(context.TODO(), nexusCustomResourceDefinition); err != nil && !errors.IsAlreadyExists(err) {
+	// Admin credentials are generated once and kept in a Secret
+	adminCredentials := map[string]string{
+		"username": "admin",
+		"password": util.RandomString(16),
+	}
+	adminSecret := kubernetes.NewStringDataSecret(workshop, r.Scheme, serviceName+"-admin", nexusNamespace.Name, labels, adminCredentials)
+	if err := r.Create(context.TODO(), adminSecret); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Custom Resource Definition", nexusCustomResourceDefinition.Name)
+		log.Infof("Created %s Secret", adminSecret.Name)
 	}
 
-	nexusServiceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, "nexus-operator", nexusNamespace.Name, labels)
-	if err := r.Create(context.TODO(), nexusServiceAccount); err != nil && !errors.IsAlreadyExists(err) {
+	adminSecretFound := &corev1.Secret{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: adminSecret.Name, Namespace: nexusNamespace.Name}, adminSecretFound); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	persistentVolumeClaim := kubernetes.NewPersistentVolumeClaim(workshop, r.Scheme, serviceName, nexusNamespace.Name, labels, "10Gi")
+	if err := r.Create(context.TODO(), persistentVolumeClaim); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Service Account", nexusServiceAccount.Name)
+		log.Infof("Created %s Persistent Volume Claim", persistentVolumeClaim.Name)
 	}
 
-	nexusClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, "nexus-operator", nexusNamespace.Name, labels, nexus.NewRules())
-	if err := r.Create(context.TODO(), nexusClusterRole); err != nil && !errors.IsAlreadyExists(err) {
+	// Deploy/Update Nexus
+	dep := nexus.NewDeployment(workshop, r.Scheme, serviceName, nexusNamespace.Name, labels)
+	if err := r.Create(context.TODO(), dep); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Cluster Role", nexusClusterRole.Name)
+		log.Infof("Created %s Deployment", dep.Name)
+	} else if errors.IsAlreadyExists(err) {
+		deploymentFound := &appsv1.Deployment{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: dep.Name, Namespace: nexusNamespace.Name}, deploymentFound); err != nil {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			// Fields defaulted by the API server are ignored
+			if !equality.Semantic.DeepDerivative(dep.Spec.Template, deploymentFound.Spec.Template) {
+				// Update Nexus
+				deploymentFound.Spec.Template = dep.Spec.Template
+				if err := r.Update(context.TODO(), deploymentFound); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s Deployment", deploymentFound.Name)
+			}
+		}
 	}
 
-	nexusClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, "nexus-operator", nexusNamespace.Name, labels, "nexus-operator", "nexus-operator", "ClusterRole")
-	if err := r.Create(context.TODO(), nexusClusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	// Create Service
+	service := kubernetes.NewService(workshop, r.Scheme, serviceName, nexusNamespace.Name, labels, []string{"http", "docker"}, []int32{8081, 5000})
+	if err := r.Create(context.TODO(), service); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Cluster Role Binding", nexusClusterRoleBinding.Name)
+		log.Infof("Created %s Service", service.Name)
+	}
+
+	// Expose Service
+	nexusHost, err := r.exposeService(workshop, serviceName, nexusNamespace.Name, labels, serviceName, 8081, true, appsHostnameSuffix)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if _, err := r.exposeService(workshop, serviceName+"-registry", nexusNamespace.Name, labels, serviceName, 5000, true, appsHostnameSuffix); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Wait for Server to be running
+	if !kubernetes.GetK8Client().GetDeploymentStatus(serviceName, nexusNamespace.Name) {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 1}, nil
+	}
+
+	nexusURL := "https://" + nexusHost
+	adminUsername := string(adminSecretFound.Data["username"])
+	adminPassword := string(adminSecretFound.Data["password"])
+
+	if result, err := initNexus(nexusURL, adminUsername, adminPassword); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	if result, err := createNexusUserRole(nexusURL, adminUsername, adminPassword); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	// The password of the existing users is changed when the one of the Workshop changed
+	passwordHash := fmt.Sprintf("%x", sha256.Sum256([]byte(workshop.Spec.User.Password)))
+	passwordChanged := adminSecretFound.Annotations[kubernetes.PasswordHashAnnotation] != passwordHash
+
+	for id := 1; id <= users; id++ {
+		username := fmt.Sprintf("user%d", id)
+
+		if result, err := createNexusUser(workshop, username, nexusURL, adminUsername, adminPassword, passwordChanged); util.IsRequeued(result, err) {
+			return result, err
+		}
+
+		if workshop.Spec.Infrastructure.Project.Enabled {
+			for _, projectName := range userProjectNames(workshop, id) {
+				if result, err := r.addNexusProjectSettings(workshop, projectName, username); util.IsRequeued(result, err) {
+					return result, err
+				}
+			}
+		}
+	}
+
+	if passwordChanged {
+		if adminSecretFound.Annotations == nil {
+			adminSecretFound.Annotations = map[string]string{}
+		}
+		adminSecretFound.Annotations[kubernetes.PasswordHashAnnotation] = passwordHash
+		if err := r.Update(context.TODO(), adminSecretFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Updated %s Secret", adminSecretFound.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// initNexus secures the admin account and configures Nexus through its REST API
+func initNexus(nexusURL string, adminUsername string, adminPassword string) (reconcile.Result, error) {
+
+	// Change the default admin password unless it has already been done
+	statusCode, _, err := callNexusAPI("GET", nexusURL+"/service/rest/v1/repositories", adminUsername, adminPassword, "", nil)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if statusCode == http.StatusUnauthorized {
+		statusCode, _, err = callNexusAPI("PUT", nexusURL+"/service/rest/v1/security/users/"+adminUsername+"/change-password",
+			adminUsername, nexusDefaultAdminPassword, "text/plain", []byte(adminPassword))
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if statusCode != http.StatusNoContent {
+			log.Errorf("Error when changing the Nexus admin password (%d)", statusCode)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+		log.Infof("Changed the Nexus admin password")
+	} else if statusCode != http.StatusOK {
+		log.Errorf("Error when connecting to Nexus (%d)", statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	// Allow anonymous read access so builds can pull dependencies
+	anonymous := map[string]interface{}{
+		"enabled":   true,
+		"userId":    "anonymous",
+		"realmName": "NexusAuthorizingRealm",
+	}
+	body, err := json.Marshal(anonymous)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	statusCode, _, err = callNexusAPI("PUT", nexusURL+"/service/rest/v1/security/anonymous", adminUsername, adminPassword, "application/json", body)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if statusCode != http.StatusOK {
+		log.Errorf("Error when enabling Nexus anonymous access (%d)", statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	// Activate the realms used by the npm and docker clients
+	realms := []string{"NexusAuthenticatingRealm", "NexusAuthorizingRealm", "NpmToken", "DockerToken"}
+	body, err = json.Marshal(realms)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	statusCode, _, err = callNexusAPI("PUT", nexusURL+"/service/rest/v1/security/realms/active", adminUsername, adminPassword, "application/json", body)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if statusCode != http.StatusNoContent {
+		log.Errorf("Error when activating Nexus realms (%d)", statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	// Create the missing repositories
+	statusCode, body, err = callNexusAPI("GET", nexusURL+"/service/rest/v1/repositories", adminUsername, adminPassword, "", nil)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if statusCode != http.StatusOK {
+		log.Errorf("Error when listing Nexus repositories (%d)", statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
 	}
 
-	nexusOperator := kubernetes.NewAnsibleOperatorDeployment(workshop, r.Scheme, "nexus-operator", nexusNamespace.Name, labels, "quay.io/mcouliba/nexus-operator:v0.10", "nexus-operator")
-	if err := r.Create(context.TODO(), nexusOperator); err != nil && !errors.IsAlreadyExists(err) {
+	existingRepositories := []nexus.Repository{}
+	if err := json.Unmarshal(body, &existingRepositories); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	repositoryNames := []string{}
+	for _, repository := range existingRepositories {
+		repositoryNames = append(repositoryNames, repository.Name)
+	}
+
+	for _, repository := range nexus.NewRepositories() {
+		if util.StringInSlice(repository.Name, repositoryNames) {
+			continue
+		}
+
+		body, err = json.Marshal(repository)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		statusCode, _, err = callNexusAPI("POST", nexusURL+"/service/rest/v1/repositories/"+repository.Format+"/"+repository.Type,
+			adminUsername, adminPassword, "application/json", body)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if statusCode != http.StatusCreated {
+			log.Errorf("Error when creating %s repository in Nexus (%d)", repository.Name, statusCode)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+		log.Infof("Created %s repository in Nexus", repository.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func createNexusUserRole(nexusURL string, adminUsername string, adminPassword string) (reconcile.Result, error) {
+
+	role := nexus.NewUserRole()
+
+	statusCode, _, err := callNexusAPI("GET", nexusURL+"/service/rest/v1/security/roles/"+role.ID, adminUsername, adminPassword, "", nil)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if statusCode == http.StatusOK {
+		return reconcile.Result{}, nil
+	}
+
+	body, err := json.Marshal(role)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	statusCode, _, err = callNexusAPI("POST", nexusURL+"/service/rest/v1/security/roles", adminUsername, adminPassword, "application/json", body)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if statusCode != http.StatusOK {
+		log.Errorf("Error when creating %s role in Nexus (%d)", role.ID, statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+	log.Infof("Created %s role in Nexus", role.ID)
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// createNexusUser creates the Nexus user, or changes its password if the one of the Workshop changed
+func createNexusUser(workshop *workshopv1.Workshop, username string, nexusURL string,
+	adminUsername string, adminPassword string, passwordChanged bool) (reconcile.Result, error) {
+
+	// The userId filter is a prefix match, so look for the exact user
+	statusCode, body, err := callNexusAPI("GET", nexusURL+"/service/rest/v1/security/users?userId="+username, adminUsername, adminPassword, "", nil)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if statusCode != http.StatusOK {
+		log.Errorf("Error when getting %s user from Nexus (%d)", username, statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	existingUsers := []map[string]interface{}{}
+	if err := json.Unmarshal(body, &existingUsers); err != nil {
+		return reconcile.Result{}, err
+	}
+	for _, existingUser := range existingUsers {
+		if existingUser["userId"] != username {
+			continue
+		}
+		if !passwordChanged {
+			return reconcile.Result{}, nil
+		}
+
+		statusCode, _, err := callNexusAPI("PUT", nexusURL+"/service/rest/v1/security/users/"+username+"/change-password",
+			adminUsername, adminPassword, "text/plain", []byte(workshop.Spec.User.Password))
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if statusCode != http.StatusNoContent {
+			log.Errorf("Error when changing the password of %s user in Nexus (%d)", username, statusCode)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+		log.Infof("Changed the password of %s user in Nexus", username)
+
+		//Success
+		return reconcile.Result{}, nil
+	}
+
+	body, err = json.Marshal(nexus.NewUser(username, workshop.Spec.User.Password))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	statusCode, _, err = callNexusAPI("POST", nexusURL+"/service/rest/v1/security/users", adminUsername, adminPassword, "application/json", body)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if statusCode != http.StatusOK {
+		log.Errorf("Error when creating %s user in Nexus (%d)", username, statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+	log.Infof("Created %s user in Nexus", username)
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// addNexusProjectSettings makes the Nexus settings of a user available to the builds of a project
+func (r *WorkshopReconciler) addNexusProjectSettings(workshop *workshopv1.Workshop, projectName string, username string) (reconcile.Result, error) {
+
+	labels := map[string]string{
+		"app.kubernetes.io/part-of": "nexus",
+	}
+	nexusNamespace := workshop.Status.Namespaces.Nexus
+
+	settings := map[string]string{
+		"settings.xml": nexus.NewMavenSettings(nexusNamespace, username, workshop.Spec.User.Password),
+		".npmrc":       nexus.NewNpmrc(nexusNamespace, username, workshop.Spec.User.Password),
+	}
+	settingsSecret := kubernetes.NewStringDataSecret(workshop, r.Scheme, "nexus-settings", projectName, labels, settings)
+	if result, err := r.manageSettingsSecret(settingsSecret); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	// Mirror variables understood by the S2I builder images
+	mirrors := map[string]string{
+		"MAVEN_MIRROR_URL": nexus.MavenRepositoryURL(nexusNamespace),
+		"NPM_MIRROR":       nexus.NpmRepositoryURL(nexusNamespace),
+	}
+	mirrorsConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, "nexus", projectName, labels, mirrors)
+	if err := r.Create(context.TODO(), mirrorsConfigMap); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Operator", nexusOperator.Name)
+		log.Infof("Created %s ConfigMap in %s", mirrorsConfigMap.Name, projectName)
+	} else if errors.IsAlreadyExists(err) {
+		configMapFound := &corev1.ConfigMap{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: mirrorsConfigMap.Name, Namespace: projectName}, configMapFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		if !reflect.DeepEqual(mirrorsConfigMap.Data, configMapFound.Data) {
+			configMapFound.Data = mirrorsConfigMap.Data
+			if err := r.Update(context.TODO(), configMapFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Updated %s ConfigMap in %s", configMapFound.Name, projectName)
+		}
 	}
 
-	nexusCustomResource := nexus.NewCustomResource(workshop, r.Scheme, "nexus", nexusNamespace.Name, labels)
-	if err := r.Create(context.TODO(), nexusCustomResource); err != nil && !errors.IsAlreadyExists(err) {
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// manageSettingsSecret creates the Secret holding the settings of a user
+// or updates it when the settings changed, like after a password change
+func (r *WorkshopReconciler) manageSettingsSecret(secret *corev1.Secret) (reconcile.Result, error) {
+
+	if err := r.Create(context.TODO(), secret); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Custom Resource", nexusCustomResource.Name)
+		log.Infof("Created %s Secret in %s", secret.Name, secret.Namespace)
+	} else if errors.IsAlreadyExists(err) {
+		secretFound := &corev1.Secret{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secretFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		data := map[string][]byte{}
+		for key, value := range secret.StringData {
+			data[key] = []byte(value)
+		}
+		if !reflect.DeepEqual(data, secretFound.Data) {
+			secretFound.Data = data
+			if err := r.Update(context.TODO(), secretFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Updated %s Secret in %s", secretFound.Name, secretFound.Namespace)
+		}
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
+
+func callNexusAPI(method string, requestURL string, username string, password string,
+	contentType string, body []byte) (int, []byte, error) {
+
+	var (
+		err          error
+		httpResponse *http.Response
+		httpRequest  *http.Request
+		client       = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+			// Do not follow Redirect
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	)
+
+	httpRequest, err = http.NewRequest(method, requestURL, bytes.NewBuffer(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	httpRequest.Header.Set("Authorization", "Basic "+util.GetBasicAuth(username, password))
+	httpRequest.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		httpRequest.Header.Set("Content-Type", contentType)
+	}
+
+	httpResponse, err = client.Do(httpRequest)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return httpResponse.StatusCode, responseBody, nil
+}
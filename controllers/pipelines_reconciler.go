@@ -32,16 +32,26 @@ func (r *WorkshopReconciler) addPipelines(workshop *workshopv1.Workshop) (reconc
 	channel := workshop.Spec.Infrastructure.Pipeline.OperatorHub.Channel
 	clusterServiceVersion := workshop.Spec.Infrastructure.Pipeline.OperatorHub.ClusterServiceVersion
 
-	pipelineSubscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, name, "openshift-operators",
+	pipelineSubscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, name, r.operatorsNamespace(),
 		name, channel, clusterServiceVersion)
+	if r.Platform != kubernetes.OpenShift {
+		// OperatorHub.io ships the upstream Tekton operator
+		name = "tektoncd-operator"
+		pipelineSubscription = kubernetes.NewCommunitySubscription(workshop, r.Scheme, name, r.operatorsNamespace(),
+			name, channel, clusterServiceVersion)
+		r.useCatalog(pipelineSubscription)
+	}
 	if err := r.Create(context.TODO(), pipelineSubscription); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Subscription", pipelineSubscription.Name)
 	}
+	if err := r.acquireShared(workshop, pipelineSubscription); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// Approve the installation
-	if err := r.ApproveInstallPlan(clusterServiceVersion, name, "openshift-operators"); err != nil {
+	if err := r.ApproveInstallPlan(clusterServiceVersion, name, r.operatorsNamespace()); err != nil {
 		log.Infof("Waiting for Subscription to create InstallPlan for %s", name)
 		return reconcile.Result{Requeue: true}, nil
 	}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/kubernetes"
+	routev1 "github.com/openshift/api/route/v1"
+	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/prometheus/common/log"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// podSecurityEnforceLabel is the Pod Security admission label enforcing a level on a namespace
+const podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+// podSecurityLevels are the Pod Security admission levels, from the most to the least restrictive
+var podSecurityLevels = []string{"restricted", "baseline", "privileged"}
+
+// operatorsNamespace returns the namespace of the operators installed for all namespaces
+func (r *WorkshopReconciler) operatorsNamespace() string {
+	if r.Platform == kubernetes.OpenShift {
+		return "openshift-operators"
+	}
+	return "operators"
+}
+
+// useCatalog points the Subscription to the OperatorHub.io catalog outside of OpenShift
+func (r *WorkshopReconciler) useCatalog(subscription *olmv1alpha1.Subscription) {
+	if r.Platform == kubernetes.OpenShift {
+		return
+	}
+	subscription.Labels["csc-owner-name"] = "operatorhubio-catalog"
+	subscription.Labels["csc-owner-namespace"] = "olm"
+	subscription.Spec.CatalogSource = "operatorhubio-catalog"
+	subscription.Spec.CatalogSourceNamespace = "olm"
+}
+
+// isOpenShiftOnly returns true when the component requires OpenShift and cannot be installed on the platform.
+// It is reported with the condition of the component, set back to true once the component is supported.
+func (r *WorkshopReconciler) isOpenShiftOnly(workshop *workshopv1.Workshop,
+	conditionType workshopv1.WorkshopConditionType, component string, openshiftOnly bool) (bool, error) {
+
+	if !openshiftOnly || r.Platform == kubernetes.OpenShift {
+		for _, condition := range workshop.Status.Conditions {
+			if condition.Type == conditionType && condition.Status != corev1.ConditionTrue {
+				return false, r.updateCondition(workshop, conditionType, corev1.ConditionTrue, "Supported", "")
+			}
+		}
+		return false, nil
+	}
+	log.Infof("Skipping %s, only available on OpenShift", component)
+	return true, r.updateCondition(workshop, conditionType, corev1.ConditionFalse, "Unsupported",
+		fmt.Sprintf("%s is only available on OpenShift", component))
+}
+
+// exposeService exposes the service with a Route on OpenShift or an Ingress on Kubernetes and returns its host
+func (r *WorkshopReconciler) exposeService(workshop *workshopv1.Workshop, name string, namespace string,
+	labels map[string]string, serviceName string, port int32, secured bool, appsHostnameSuffix string) (string, error) {
+
+	if r.Platform != kubernetes.OpenShift {
+		host := fmt.Sprintf("%s-%s.%s", name, namespace, appsHostnameSuffix)
+		ingress := kubernetes.NewIngress(workshop, r.Scheme, name, namespace, labels, serviceName, port, host, secured,
+			workshop.Spec.IngressClassName)
+		if err := r.Create(context.TODO(), ingress); err != nil && !errors.IsAlreadyExists(err) {
+			return "", err
+		} else if err == nil {
+			log.Infof("Created %s Ingress", ingress.GetName())
+		}
+		return host, nil
+	}
+
+	route := kubernetes.NewRoute(workshop, r.Scheme, name, namespace, labels, serviceName, port)
+	if secured {
+		route = kubernetes.NewSecuredRoute(workshop, r.Scheme, name, namespace, labels, serviceName, port)
+	}
+	if err := r.Create(context.TODO(), route); err != nil && !errors.IsAlreadyExists(err) {
+		return "", err
+	} else if err == nil {
+		log.Infof("Created %s Route", route.Name)
+	}
+
+	routeFound := &routev1.Route{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: route.Name, Namespace: namespace}, routeFound); err != nil {
+		log.Errorf("Failed to find %s route", route.Name)
+		return "", err
+	}
+	return routeFound.Spec.Host, nil
+}
+
+// deleteExposure deletes the Route or the Ingress created by exposeService
+func (r *WorkshopReconciler) deleteExposure(name string, namespace string) error {
+	if r.Platform != kubernetes.OpenShift {
+		ingressFound := &unstructured.Unstructured{}
+		ingressFound.SetGroupVersionKind(kubernetes.IngressGVK)
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, ingressFound); err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if err := r.Delete(context.TODO(), ingressFound); err != nil {
+			return err
+		}
+		log.Infof("Deleted %s Ingress", ingressFound.GetName())
+		return nil
+	}
+
+	routeFound := &routev1.Route{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, routeFound); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := r.Delete(context.TODO(), routeFound); err != nil {
+		return err
+	}
+	log.Infof("Deleted %s Route", routeFound.Name)
+	return nil
+}
+
+// addPodSecurityLevel relaxes the Pod Security admission level enforced on the namespace
+// when it is stricter than the level required by a workload
+func (r *WorkshopReconciler) addPodSecurityLevel(namespace string, level string) error {
+	namespaceFound := &corev1.Namespace{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: namespace}, namespaceFound); err != nil {
+		return err
+	}
+
+	// An unlabeled namespace enforces no level, and the level granted
+	// to another workload of the namespace is never lowered
+	enforced, found := namespaceFound.Labels[podSecurityEnforceLabel]
+	if !found || podSecurityLevelIndex(enforced) >= podSecurityLevelIndex(level) {
+		return nil
+	}
+
+	if namespaceFound.Labels == nil {
+		namespaceFound.Labels = map[string]string{}
+	}
+	namespaceFound.Labels[podSecurityEnforceLabel] = level
+	if err := r.Update(context.TODO(), namespaceFound); err != nil {
+		return err
+	}
+	log.Infof("Enforced %s Pod Security level on %s Namespace", level, namespace)
+	return nil
+}
+
+func podSecurityLevelIndex(level string) int {
+	for i, podSecurityLevel := range podSecurityLevels {
+		if podSecurityLevel == level {
+			return i
+		}
+	}
+	return -1
+}
@@ -131,12 +131,9 @@ func (r *WorkshopReconciler) addUpdateUsernameDistribution(workshop *workshopv1.
 		log.Infof("Created %s Service", service.Name)
 	}
 
-	// Create Route
-	route := kubernetes.NewSecuredRoute(workshop, r.Scheme, serviceName, workshop.Namespace, labels, serviceName, 8080)
-	if err := r.Create(context.TODO(), route); err != nil && !errors.IsAlreadyExists(err) {
+	// Expose Service
+	if _, err := r.exposeService(workshop, serviceName, workshop.Namespace, labels, serviceName, 8080, true, appsHostnameSuffix); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Route", route.Name)
 	}
 
 	//Success
@@ -1,17 +1,30 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
 
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/argocd"
+	"github.com/mcouliba/workshop-operator/common/codeready"
 	"github.com/mcouliba/workshop-operator/common/kubernetes"
 	"github.com/mcouliba/workshop-operator/common/util"
 	"github.com/prometheus/common/log"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -22,49 +35,295 @@ func (r *WorkshopReconciler) reconcileProject(workshop *workshopv1.Workshop, use
 	id := 1
 	for {
 		username := fmt.Sprintf("user%d", id)
-		stagingProjectName := fmt.Sprintf("%s%d", workshop.Spec.Infrastructure.Project.StagingName, id)
+		projects := userProjects(workshop, id)
 
 		if id <= users && enabledProject {
-			// Project
-			if workshop.Spec.Infrastructure.Project.StagingName != "" {
-				if result, err := r.addProject(workshop, stagingProjectName, username); util.IsRequeued(result, err) {
+			// Projects
+			for _, project := range projects {
+				if result, err := r.addProject(workshop, project, username); util.IsRequeued(result, err) {
 					return result, err
 				}
 			}
 
 		} else {
-			stagingProjectNamespace := kubernetes.NewNamespace(workshop, r.Scheme, stagingProjectName)
-			stagingProjectNamespaceFound := &corev1.Namespace{}
-			stagingProjectNamespaceErr := r.Get(context.TODO(), types.NamespacedName{Name: stagingProjectNamespace.Name}, stagingProjectNamespaceFound)
-
-			if stagingProjectNamespaceErr != nil && errors.IsNotFound(stagingProjectNamespaceErr) {
-				break
-			}
+			projectFound := false
+			for _, project := range projects {
+				projectNamespace := kubernetes.NewNamespace(workshop, r.Scheme, project.name)
+				projectNamespaceFound := &corev1.Namespace{}
+				if err := r.Get(context.TODO(), types.NamespacedName{Name: projectNamespace.Name}, projectNamespaceFound); err != nil {
+					if errors.IsNotFound(err) {
+						continue
+					}
+					return reconcile.Result{}, err
+				}
 
-			if !(stagingProjectNamespaceErr != nil && errors.IsNotFound(stagingProjectNamespaceErr)) {
-				if result, err := r.deleteProject(stagingProjectNamespace); util.IsRequeued(result, err) {
+				projectFound = true
+				// The namespaces which were not created by the Workshop are kept
+				if !metav1.IsControlledBy(projectNamespaceFound, workshop) {
+					continue
+				}
+				if result, err := r.deleteProject(projectNamespaceFound); util.IsRequeued(result, err) {
 					return result, err
 				}
 			}
+
+			if !projectFound {
+				break
+			}
 		}
 
 		id++
 	}
 
+	if result, err := r.deleteOrphanProjects(workshop, users); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// userProjectLabels identifies the projects of the users of the Workshop
+func userProjectLabels(workshop *workshopv1.Workshop) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/component": "user-project",
+		kubernetes.WorkshopLabel:      kubernetes.WorkshopLabelValue(workshop),
+	}
+}
+
+// deleteOrphanProjects deletes the projects of the Workshop which match no current project template,
+// like after a template was renamed or removed
+func (r *WorkshopReconciler) deleteOrphanProjects(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
+	projectNames := map[string]bool{}
+	if workshop.Spec.Infrastructure.Project.Enabled {
+		for id := 1; id <= users; id++ {
+			for _, projectName := range userProjectNames(workshop, id) {
+				projectNames[projectName] = true
+			}
+		}
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := r.List(context.TODO(), namespaces, client.MatchingLabels(userProjectLabels(workshop))); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range namespaces.Items {
+		if projectNames[namespaces.Items[i].Name] || !metav1.IsControlledBy(&namespaces.Items[i], workshop) {
+			continue
+		}
+		if result, err := r.deleteProject(&namespaces.Items[i]); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) addProject(workshop *workshopv1.Workshop, projectName string, username string) (reconcile.Result, error) {
+// userProject is a project of a user rendered from a project template
+type userProject struct {
+	name        string
+	displayName string
+	template    workshopv1.ProjectTemplateSpec
+}
+
+// projectTemplates returns the project templates of the Workshop,
+// StagingName being the single template <StagingName>{{.UserID}}
+func projectTemplates(workshop *workshopv1.Workshop) []workshopv1.ProjectTemplateSpec {
+	project := workshop.Spec.Infrastructure.Project
+	if len(project.Templates) > 0 {
+		return project.Templates
+	}
+	if project.StagingName == "" {
+		return nil
+	}
+	return []workshopv1.ProjectTemplateSpec{
+		{
+			Name:        project.StagingName + "{{.UserID}}",
+			ServiceMesh: true,
+			GitOps:      true,
+		},
+	}
+}
+
+// projectTemplateData is the data of the project template names
+type projectTemplateData struct {
+	Username string
+	UserID   int
+}
+
+// templateActionRegexp matches the actions of a project template name
+var templateActionRegexp = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
+// templateActionPatterns match the values rendered by the actions of a project template name
+var templateActionPatterns = map[string]string{
+	"{{.Username}}": "user[0-9]+",
+	"{{.UserID}}":   "[0-9]+",
+}
+
+// renderProjectTemplate renders a project template text for the user
+func renderProjectTemplate(text string, id int) (string, error) {
+	tmpl, err := template.New("project").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, projectTemplateData{Username: fmt.Sprintf("user%d", id), UserID: id}); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
+
+// renderUserProject renders the project of the user from a template and validates its name
+func renderUserProject(workshop *workshopv1.Workshop, template workshopv1.ProjectTemplateSpec, id int) (userProject, error) {
+	name, err := renderProjectTemplate(template.Name, id)
+	if err != nil {
+		return userProject{}, err
+	}
+	name = kubernetes.PrefixedName(workshop, name)
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return userProject{}, fmt.Errorf("invalid project name %s: %s", name, strings.Join(errs, ", "))
+	}
+
+	displayName, err := renderProjectTemplate(template.DisplayName, id)
+	if err != nil {
+		return userProject{}, err
+	}
+
+	return userProject{
+		name:        name,
+		displayName: displayName,
+		template:    template,
+	}, nil
+}
+
+// validateProjectTemplates checks that the project templates render valid namespace names,
+// the longest ones being the ones of the last user
+func validateProjectTemplates(workshop *workshopv1.Workshop, users int) error {
+	if users < 1 {
+		users = 1
+	}
+	for _, template := range projectTemplates(workshop) {
+		if _, err := renderUserProject(workshop, template, users); err != nil {
+			return fmt.Errorf("project template %s: %s", template.Name, err)
+		}
+	}
+	return nil
+}
+
+// userProjects returns the projects of the user,
+// the invalid templates are reported by validateProjectTemplates and skipped
+func userProjects(workshop *workshopv1.Workshop, id int) []userProject {
+	projects := []userProject{}
+	for _, template := range projectTemplates(workshop) {
+		project, err := renderUserProject(workshop, template, id)
+		if err != nil {
+			log.Errorf("Skipping the project template %s: %s", template.Name, err)
+			continue
+		}
+		projects = append(projects, project)
+	}
+	return projects
+}
+
+// userProjectNames returns the names of the projects of the user
+func userProjectNames(workshop *workshopv1.Workshop, id int) []string {
+	names := []string{}
+	for _, project := range userProjects(workshop, id) {
+		names = append(names, project.name)
+	}
+	return names
+}
+
+// isUserProject returns true if the namespace follows the naming of a project template of the workshop
+func isUserProject(workshop *workshopv1.Workshop, namespace string) bool {
+	for _, template := range projectTemplates(workshop) {
+		name := kubernetes.PrefixedName(workshop, template.Name)
+		actions := templateActionRegexp.FindAllString(name, -1)
+		pattern := ""
+		for i, literal := range templateActionRegexp.Split(name, -1) {
+			pattern += regexp.QuoteMeta(literal)
+			if i >= len(actions) {
+				continue
+			}
+			actionPattern, found := templateActionPatterns[strings.Join(strings.Fields(actions[i]), "")]
+			if !found {
+				actionPattern = "[-a-z0-9]+"
+			}
+			pattern += actionPattern
+		}
+		if matched, err := regexp.MatchString("^"+pattern+"$", namespace); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *WorkshopReconciler) addProject(workshop *workshopv1.Workshop, project userProject, username string) (reconcile.Result, error) {
 
-	projectNamespace := kubernetes.NewNamespace(workshop, r.Scheme, projectName)
+	projectNamespace := kubernetes.NewNamespace(workshop, r.Scheme, project.name)
+	projectNamespace.Labels = map[string]string{}
+	for key, value := range project.template.Labels {
+		projectNamespace.Labels[key] = value
+	}
+	for key, value := range userProjectLabels(workshop) {
+		projectNamespace.Labels[key] = value
+	}
+	if project.displayName != "" {
+		projectNamespace.Annotations = map[string]string{
+			"openshift.io/display-name": project.displayName,
+		}
+	}
 	if err := r.Create(context.TODO(), projectNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Namespace", projectNamespace.Name)
+	} else if errors.IsAlreadyExists(err) {
+		projectNamespaceFound := &corev1.Namespace{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: projectNamespace.Name}, projectNamespaceFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		// Labels and annotations set by the cluster or other components are kept,
+		// and the namespaces which were not created by the Workshop are left as they are
+		updated := false
+		if metav1.IsControlledBy(projectNamespaceFound, workshop) {
+			for key, value := range projectNamespace.Labels {
+				if projectNamespaceFound.Labels[key] != value {
+					if projectNamespaceFound.Labels == nil {
+						projectNamespaceFound.Labels = map[string]string{}
+					}
+					projectNamespaceFound.Labels[key] = value
+					updated = true
+				}
+			}
+			for key, value := range projectNamespace.Annotations {
+				if projectNamespaceFound.Annotations[key] != value {
+					if projectNamespaceFound.Annotations == nil {
+						projectNamespaceFound.Annotations = map[string]string{}
+					}
+					projectNamespaceFound.Annotations[key] = value
+					updated = true
+				}
+			}
+		}
+		if updated {
+			if err := r.Update(context.TODO(), projectNamespaceFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Updated %s Namespace", projectNamespaceFound.Name)
+		}
+	}
+
+	if result, err := r.manageRoles(workshop, project, username); util.IsRequeued(result, err) {
+		return result, err
 	}
 
-	if result, err := r.manageRoles(workshop, projectNamespace.Name, username); err != nil {
+	if result, err := r.manageQuota(workshop, projectNamespace.Name, username); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	if result, err := r.manageIsolation(workshop, projectNamespace.Name, username); util.IsRequeued(result, err) {
 		return result, err
 	}
 
@@ -84,7 +343,13 @@ func (r *WorkshopReconciler) deleteProject(namespaces *corev1.Namespace) (reconc
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) manageRoles(workshop *workshopv1.Workshop, projectName string, username string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) manageRoles(workshop *workshopv1.Workshop, project userProject, username string) (reconcile.Result, error) {
+
+	projectName := project.name
+	userRole := project.template.UserRole
+	if userRole == "" {
+		userRole = "edit"
+	}
 
 	labels := map[string]string{
 		"app.kubernetes.io/part-of": "project",
@@ -100,11 +365,24 @@ func (r *WorkshopReconciler) manageRoles(workshop *workshopv1.Workshop, projectN
 
 	// User
 	userRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, username+"-project", projectName, labels,
-		users, "edit", "ClusterRole")
+		users, userRole, "ClusterRole")
 	if err := r.Create(context.TODO(), userRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Role Binding", userRoleBinding.Name)
+	} else if errors.IsAlreadyExists(err) {
+		userRoleBindingFound := &rbac.RoleBinding{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: userRoleBinding.Name, Namespace: projectName}, userRoleBindingFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		// The role of a Role Binding cannot be changed, the binding is recreated with the new role
+		if userRoleBindingFound.RoleRef.Name != userRole {
+			if err := r.Delete(context.TODO(), userRoleBindingFound); err != nil && !errors.IsNotFound(err) {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Deleted %s Role Binding", userRoleBindingFound.Name)
+			return reconcile.Result{Requeue: true}, nil
+		}
 	}
 
 	// Default
@@ -119,17 +397,272 @@ func (r *WorkshopReconciler) manageRoles(workshop *workshopv1.Workshop, projectN
 	argocdUsers := []rbac.Subject{}
 	userSubject = rbac.Subject{
 		Kind: rbac.UserKind,
-		Name: "system:serviceaccount:argocd:argocd-argocd-application-controller",
+		Name: argocd.ApplicationControllerUser(argocdInstance(workshop)),
 	}
 	argocdUsers = append(argocdUsers, userSubject)
 
 	//Argo CD
 	argocdEditRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
 		username+"-argocd", projectName, labels, argocdUsers, "edit", "ClusterRole")
-	if err := r.Create(context.TODO(), argocdEditRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
-		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Role Binding", argocdEditRoleBinding.Name)
+	if project.template.GitOps {
+		if err := r.Create(context.TODO(), argocdEditRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Created %s Role Binding", argocdEditRoleBinding.Name)
+		}
+	} else {
+		if err := r.Delete(context.TODO(), argocdEditRoleBinding); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Deleted %s Role Binding", argocdEditRoleBinding.Name)
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// manageQuota applies the ResourceQuota and the LimitRange of the Workshop to a namespace of the user
+func (r *WorkshopReconciler) manageQuota(workshop *workshopv1.Workshop, namespace string, username string) (reconcile.Result, error) {
+
+	labels := map[string]string{
+		"app.kubernetes.io/part-of": "project",
+	}
+
+	// Resource Quota
+	resourceQuotaSpec := workshop.Spec.Infrastructure.Project.ResourceQuota
+	if resourceQuotaSpec != nil {
+		resourceQuota := kubernetes.NewResourceQuota(workshop, r.Scheme, username+"-quota", namespace, labels, *resourceQuotaSpec)
+		if err := r.Create(context.TODO(), resourceQuota); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Created %s Resource Quota in %s", resourceQuota.Name, namespace)
+		} else if errors.IsAlreadyExists(err) {
+			resourceQuotaFound := &corev1.ResourceQuota{}
+			if err := r.Get(context.TODO(), types.NamespacedName{Name: resourceQuota.Name, Namespace: namespace}, resourceQuotaFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			if !reflect.DeepEqual(resourceQuota.Spec, resourceQuotaFound.Spec) {
+				resourceQuotaFound.Spec = resourceQuota.Spec
+				if err := r.Update(context.TODO(), resourceQuotaFound); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s Resource Quota in %s", resourceQuota.Name, namespace)
+			}
+		}
+	} else {
+		resourceQuota := &corev1.ResourceQuota{}
+		resourceQuota.Name = username + "-quota"
+		resourceQuota.Namespace = namespace
+		if err := r.Delete(context.TODO(), resourceQuota); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Deleted %s Resource Quota in %s", resourceQuota.Name, namespace)
+		}
+	}
+
+	// Limit Range
+	limitRangeSpec := workshop.Spec.Infrastructure.Project.LimitRange
+	if limitRangeSpec != nil {
+		limitRange := kubernetes.NewLimitRange(workshop, r.Scheme, username+"-limits", namespace, labels, *limitRangeSpec)
+		if err := r.Create(context.TODO(), limitRange); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Created %s Limit Range in %s", limitRange.Name, namespace)
+		} else if errors.IsAlreadyExists(err) {
+			limitRangeFound := &corev1.LimitRange{}
+			if err := r.Get(context.TODO(), types.NamespacedName{Name: limitRange.Name, Namespace: namespace}, limitRangeFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			if !reflect.DeepEqual(limitRange.Spec, limitRangeFound.Spec) {
+				limitRangeFound.Spec = limitRange.Spec
+				if err := r.Update(context.TODO(), limitRangeFound); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s Limit Range in %s", limitRange.Name, namespace)
+			}
+		}
+	} else {
+		limitRange := &corev1.LimitRange{}
+		limitRange.Name = username + "-limits"
+		limitRange.Namespace = namespace
+		if err := r.Delete(context.TODO(), limitRange); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Deleted %s Limit Range in %s", limitRange.Name, namespace)
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// isolationPolicy is a Network Policy of an isolated project, it is deleted when its ingress rules are nil
+type isolationPolicy struct {
+	name    string
+	ingress []networkingv1.NetworkPolicyIngressRule
+}
+
+// manageIsolation denies the incoming traffic from the other namespaces to a project of the user
+func (r *WorkshopReconciler) manageIsolation(workshop *workshopv1.Workshop, namespace string, username string) (reconcile.Result, error) {
+
+	labels := map[string]string{
+		"app.kubernetes.io/part-of": "project",
+	}
+
+	for _, policy := range r.isolationPolicies(workshop, username) {
+		networkPolicy := kubernetes.NewNetworkPolicy(workshop, r.Scheme, policy.name, namespace, labels, policy.ingress)
+
+		if !workshop.Spec.Infrastructure.Project.Isolation || policy.ingress == nil {
+			if err := r.Delete(context.TODO(), networkPolicy); err != nil && !errors.IsNotFound(err) {
+				return reconcile.Result{}, err
+			} else if err == nil {
+				log.Infof("Deleted %s Network Policy in %s", networkPolicy.Name, namespace)
+			}
+			continue
+		}
+
+		if err := r.Create(context.TODO(), networkPolicy); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Created %s Network Policy in %s", networkPolicy.Name, namespace)
+		} else if errors.IsAlreadyExists(err) {
+			networkPolicyFound := &networkingv1.NetworkPolicy{}
+			if err := r.Get(context.TODO(), types.NamespacedName{Name: networkPolicy.Name, Namespace: namespace}, networkPolicyFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			// Empty and nil ingress rules are equal
+			if !equality.Semantic.DeepEqual(networkPolicy.Spec, networkPolicyFound.Spec) {
+				networkPolicyFound.Spec = networkPolicy.Spec
+				if err := r.Update(context.TODO(), networkPolicyFound); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s Network Policy in %s", networkPolicy.Name, namespace)
+			}
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// isolationPolicies returns the policy denying all the incoming traffic to a project of the user
+// followed by its exceptions
+func (r *WorkshopReconciler) isolationPolicies(workshop *workshopv1.Workshop, username string) []isolationPolicy {
+	fromNamespaces := func(namespaces ...string) []networkingv1.NetworkPolicyIngressRule {
+		peers := []networkingv1.NetworkPolicyPeer{}
+		for _, namespace := range namespaces {
+			if namespace != "" {
+				peers = append(peers, kubernetes.NewNamespacePeer(map[string]string{kubernetes.NamespaceNameLabel: namespace}))
+			}
+		}
+		if len(peers) == 0 {
+			return nil
+		}
+		return []networkingv1.NetworkPolicyIngressRule{{From: peers}}
+	}
+	fromPolicyGroup := func(group string) []networkingv1.NetworkPolicyIngressRule {
+		return []networkingv1.NetworkPolicyIngressRule{
+			{From: []networkingv1.NetworkPolicyPeer{
+				kubernetes.NewNamespacePeer(map[string]string{"network.openshift.io/policy-group": group}),
+			}},
+		}
+	}
+
+	infrastructure := workshop.Spec.Infrastructure
+	namespaces := workshop.Status.Namespaces
+
+	policies := []isolationPolicy{
+		{name: "deny-by-default", ingress: []networkingv1.NetworkPolicyIngressRule{}},
+		{
+			name:    "allow-from-same-namespace",
+			ingress: []networkingv1.NetworkPolicyIngressRule{{From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}}},
+		},
+	}
+
+	// OpenShift labels the namespaces of its router and of its monitoring stack
+	router := isolationPolicy{name: "allow-from-ingress"}
+	monitoring := isolationPolicy{name: "allow-from-monitoring"}
+	if r.Platform == kubernetes.OpenShift {
+		router.ingress = fromPolicyGroup("ingress")
+		monitoring.ingress = fromPolicyGroup("monitoring")
+	} else {
+		router.ingress = fromNamespaces("ingress-nginx")
+	}
+	policies = append(policies, router, monitoring)
+
+	mesh := isolationPolicy{name: "allow-from-mesh-control-plane"}
+	if infrastructure.ServiceMesh.Enabled {
+		mesh.ingress = fromNamespaces(serviceMeshControlPlaneNamespace(workshop, username))
+	}
+	policies = append(policies, mesh)
+
+	components := []string{}
+	if infrastructure.Nexus.Enabled {
+		components = append(components, namespaces.Nexus)
+	}
+	if infrastructure.Gitea.Enabled {
+		components = append(components, namespaces.Gitea)
+	}
+	if infrastructure.Vault.Enabled {
+		components = append(components, namespaces.Vault)
+	}
+	// The user tests the applications of the projects from the workspaces
+	if infrastructure.CodeReadyWorkspace.Enabled {
+		components = append(components, codeready.WorkspaceNamespaceName(workshop, username))
+	}
+	policies = append(policies, isolationPolicy{name: "allow-from-workshop", ingress: fromNamespaces(components...)})
+
+	return policies
+}
+
+// usageRefreshInterval is how often the quota usage of the users is refreshed
+const usageRefreshInterval = time.Minute * 5
+
+// reconcileUsage reports the quota usage of every user, summed over their projects and their workspace namespace,
+// and requeues the Workshop to refresh it
+func (r *WorkshopReconciler) reconcileUsage(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
+
+	var usage map[string]corev1.ResourceList
+	if workshop.Spec.Infrastructure.Project.ResourceQuota != nil {
+		usage = map[string]corev1.ResourceList{}
+		for id := 1; id <= users; id++ {
+			username := fmt.Sprintf("user%d", id)
+
+			namespaces := []string{codeready.WorkspaceNamespaceName(workshop, username)}
+			if workshop.Spec.Infrastructure.Project.Enabled {
+				namespaces = append(namespaces, userProjectNames(workshop, id)...)
+			}
+
+			used := corev1.ResourceList{}
+			for _, namespace := range namespaces {
+				resourceQuotaFound := &corev1.ResourceQuota{}
+				if err := r.Get(context.TODO(), types.NamespacedName{Name: username + "-quota", Namespace: namespace}, resourceQuotaFound); err != nil {
+					if errors.IsNotFound(err) {
+						continue
+					}
+					return reconcile.Result{}, err
+				}
+				for name, quantity := range resourceQuotaFound.Status.Used {
+					total := used[name]
+					total.Add(quantity)
+					used[name] = total
+				}
+			}
+			usage[username] = used
+		}
+	}
+
+	// Quantities are compared by value, their string representation may differ
+	if !equality.Semantic.DeepEqual(workshop.Status.Usage, usage) {
+		workshop.Status.Usage = usage
+		if err := r.Status().Update(context.TODO(), workshop); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if usage != nil {
+		return reconcile.Result{RequeueAfter: usageRefreshInterval}, nil
 	}
 
 	//Success
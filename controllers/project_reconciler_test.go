@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+)
+
+func TestRenderProjectTemplate(t *testing.T) {
+	tests := []struct {
+		text     string
+		rendered string
+		err      bool
+	}{
+		{text: "dev-{{.Username}}", rendered: "dev-user3"},
+		{text: "cn-project{{.UserID}}", rendered: "cn-project3"},
+		{text: "{{ .Username }}-ci", rendered: "user3-ci"},
+		{text: "Development of {{.Username}}", rendered: "Development of user3"},
+		{text: "", rendered: ""},
+		{text: "dev-{{.Project}}", err: true},
+		{text: "dev-{{.Username", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			rendered, err := renderProjectTemplate(test.text, 3)
+			if (err != nil) != test.err {
+				t.Fatalf("error = %v, want error %t", err, test.err)
+			}
+			if rendered != test.rendered {
+				t.Errorf("rendered = %q, want %q", rendered, test.rendered)
+			}
+		})
+	}
+}
+
+func TestRenderUserProject(t *testing.T) {
+	tests := []struct {
+		name        string
+		prefix      string
+		template    workshopv1.ProjectTemplateSpec
+		projectName string
+		displayName string
+		err         bool
+	}{
+		{
+			name:        "username",
+			template:    workshopv1.ProjectTemplateSpec{Name: "dev-{{.Username}}", DisplayName: "Development of {{.Username}}"},
+			projectName: "dev-user1",
+			displayName: "Development of user1",
+		},
+		{
+			name:        "prefixed",
+			prefix:      "ws1",
+			template:    workshopv1.ProjectTemplateSpec{Name: "cn-project{{.UserID}}"},
+			projectName: "ws1-cn-project1",
+		},
+		{
+			name:     "invalid characters",
+			template: workshopv1.ProjectTemplateSpec{Name: "Dev_{{.Username}}"},
+			err:      true,
+		},
+		{
+			name:     "too long with the prefix",
+			prefix:   "ws1",
+			template: workshopv1.ProjectTemplateSpec{Name: strings.Repeat("a", 55) + "{{.Username}}"},
+			err:      true,
+		},
+		{
+			name:     "invalid display name",
+			template: workshopv1.ProjectTemplateSpec{Name: "dev-{{.Username}}", DisplayName: "{{.Project}}"},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			workshop := &workshopv1.Workshop{}
+			workshop.Spec.Prefix = test.prefix
+
+			project, err := renderUserProject(workshop, test.template, 1)
+			if (err != nil) != test.err {
+				t.Fatalf("error = %v, want error %t", err, test.err)
+			}
+			if project.name != test.projectName {
+				t.Errorf("name = %q, want %q", project.name, test.projectName)
+			}
+			if project.displayName != test.displayName {
+				t.Errorf("display name = %q, want %q", project.displayName, test.displayName)
+			}
+		})
+	}
+}
+
+func TestValidateProjectTemplates(t *testing.T) {
+	// 63 characters for the users 1 to 9, 64 from the user 10
+	longTemplate := workshopv1.ProjectTemplateSpec{Name: strings.Repeat("a", 62) + "{{.UserID}}"}
+
+	tests := []struct {
+		name      string
+		templates []workshopv1.ProjectTemplateSpec
+		users     int
+		err       bool
+	}{
+		{name: "no template", users: 10},
+		{name: "valid", templates: []workshopv1.ProjectTemplateSpec{{Name: "dev-{{.Username}}"}}, users: 10},
+		{name: "no user", templates: []workshopv1.ProjectTemplateSpec{{Name: "dev-{{.Username}}"}}, users: 0},
+		{name: "longest name valid", templates: []workshopv1.ProjectTemplateSpec{longTemplate}, users: 9},
+		{name: "longest name too long", templates: []workshopv1.ProjectTemplateSpec{longTemplate}, users: 10, err: true},
+		{
+			name:      "one invalid template",
+			templates: []workshopv1.ProjectTemplateSpec{{Name: "dev-{{.Username}}"}, {Name: "CI-{{.Username}}"}},
+			users:     1,
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			workshop := &workshopv1.Workshop{}
+			workshop.Spec.Infrastructure.Project.Templates = test.templates
+
+			if err := validateProjectTemplates(workshop, test.users); (err != nil) != test.err {
+				t.Errorf("error = %v, want error %t", err, test.err)
+			}
+		})
+	}
+}
+
+func TestIsUserProject(t *testing.T) {
+	tests := []struct {
+		name        string
+		prefix      string
+		stagingName string
+		templates   []workshopv1.ProjectTemplateSpec
+		namespace   string
+		userProject bool
+	}{
+		{name: "staging project", stagingName: "cn-project", namespace: "cn-project12", userProject: true},
+		{name: "not a user id", stagingName: "cn-project", namespace: "cn-projectx", userProject: false},
+		{name: "staging prefix", stagingName: "cn-project", namespace: "cn-project1-ci", userProject: false},
+		{name: "no template", namespace: "dev-user1", userProject: false},
+		{
+			name:        "template",
+			templates:   []workshopv1.ProjectTemplateSpec{{Name: "dev-{{.Username}}"}, {Name: "dev-{{.Username}}-ci"}},
+			namespace:   "dev-user1",
+			userProject: true,
+		},
+		{
+			name:        "template sharing the prefix of another",
+			templates:   []workshopv1.ProjectTemplateSpec{{Name: "dev-{{.Username}}"}, {Name: "dev-{{.Username}}-ci"}},
+			namespace:   "dev-user1-ci",
+			userProject: true,
+		},
+		{
+			name:        "prefix of a template without its suffix",
+			templates:   []workshopv1.ProjectTemplateSpec{{Name: "dev-{{.Username}}-ci"}},
+			namespace:   "dev-user1",
+			userProject: false,
+		},
+		{
+			name:        "longer than a template",
+			templates:   []workshopv1.ProjectTemplateSpec{{Name: "dev-{{.Username}}"}, {Name: "dev-{{.Username}}-ci"}},
+			namespace:   "dev-user1-prod",
+			userProject: false,
+		},
+		{
+			name:        "not a username",
+			templates:   []workshopv1.ProjectTemplateSpec{{Name: "dev-{{.Username}}"}},
+			namespace:   "dev-team",
+			userProject: false,
+		},
+		{
+			name:        "prefixed",
+			prefix:      "ws1",
+			templates:   []workshopv1.ProjectTemplateSpec{{Name: "dev-{{.Username}}"}},
+			namespace:   "ws1-dev-user1",
+			userProject: true,
+		},
+		{
+			name:        "without the prefix",
+			prefix:      "ws1",
+			templates:   []workshopv1.ProjectTemplateSpec{{Name: "dev-{{.Username}}"}},
+			namespace:   "dev-user1",
+			userProject: false,
+		},
+		{
+			name:        "literal dots",
+			templates:   []workshopv1.ProjectTemplateSpec{{Name: "dev.{{.Username}}"}},
+			namespace:   "dev-user1",
+			userProject: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			workshop := &workshopv1.Workshop{}
+			workshop.Spec.Prefix = test.prefix
+			workshop.Spec.Infrastructure.Project.StagingName = test.stagingName
+			workshop.Spec.Infrastructure.Project.Templates = test.templates
+
+			if userProject := isUserProject(workshop, test.namespace); userProject != test.userProject {
+				t.Errorf("isUserProject(%q) = %t, want %t", test.namespace, userProject, test.userProject)
+			}
+		})
+	}
+}
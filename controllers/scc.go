@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/kubernetes"
+	"github.com/mcouliba/workshop-operator/common/util"
+	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/prometheus/common/log"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// legacySCCs are the built-in SCCs previously edited to grant service accounts
+var legacySCCs = []string{"privileged", "anyuid"}
+
+// baselineCapabilities are the capabilities allowed by the baseline Pod Security level
+var baselineCapabilities = []string{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+	"NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+
+// addSecurityContextConstraints creates the SCC and grants its use to the service account
+func (r *WorkshopReconciler) addSecurityContextConstraints(workshop *workshopv1.Workshop,
+	scc *securityv1.SecurityContextConstraints, namespace string, serviceAccountName string) (reconcile.Result, error) {
+
+	// Kubernetes has no SCCs, the namespace enforces the matching Pod Security level instead
+	if r.Platform != kubernetes.OpenShift {
+		if err := r.addPodSecurityLevel(namespace, podSecurityLevel(scc)); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.Create(context.TODO(), scc); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s SCC", scc.Name)
+	} else if errors.IsAlreadyExists(err) {
+		sccFound := &securityv1.SecurityContextConstraints{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: scc.Name}, sccFound); err != nil {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			if !reflect.DeepEqual(scc.RunAsUser, sccFound.RunAsUser) ||
+				!reflect.DeepEqual(scc.AllowedCapabilities, sccFound.AllowedCapabilities) ||
+				!reflect.DeepEqual(scc.AllowPrivilegeEscalation, sccFound.AllowPrivilegeEscalation) {
+				sccFound.RunAsUser = scc.RunAsUser
+				sccFound.AllowedCapabilities = scc.AllowedCapabilities
+				sccFound.AllowPrivilegeEscalation = scc.AllowPrivilegeEscalation
+				if err := r.Update(context.TODO(), sccFound); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s SCC", sccFound.Name)
+			}
+		}
+	}
+
+	clusterRole := kubernetes.NewClusterRole(workshop, r.Scheme,
+		scc.Name+"-scc", "", scc.Labels, kubernetes.SecurityContextConstraintsUseRules(scc.Name))
+	if err := r.Create(context.TODO(), clusterRole); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Cluster Role", clusterRole.Name)
+	}
+
+	roleBinding := kubernetes.NewRoleBindingSA(workshop, r.Scheme,
+		clusterRole.Name, namespace, scc.Labels, serviceAccountName, clusterRole.Name, "ClusterRole")
+	if err := r.Create(context.TODO(), roleBinding); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Role Binding in %s", roleBinding.Name, namespace)
+	}
+
+	// Revert the edits of the built-in SCCs made by previous versions
+	serviceAccountUser := "system:serviceaccount:" + namespace + ":" + serviceAccountName
+	for _, legacySCC := range legacySCCs {
+		sccFound := &securityv1.SecurityContextConstraints{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: legacySCC}, sccFound); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return reconcile.Result{}, err
+		}
+
+		if util.StringInSlice(serviceAccountUser, sccFound.Users) {
+			users := []string{}
+			for _, user := range sccFound.Users {
+				if user != serviceAccountUser {
+					users = append(users, user)
+				}
+			}
+			sccFound.Users = users
+			if err := r.Update(context.TODO(), sccFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Removed %s from %s SCC", serviceAccountUser, sccFound.Name)
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// podSecurityLevel returns the Pod Security level admitting the pods allowed by the SCC
+func podSecurityLevel(scc *securityv1.SecurityContextConstraints) string {
+	if scc.RunAsUser.Type == securityv1.RunAsUserStrategyRunAsAny {
+		return "privileged"
+	}
+	for _, capability := range scc.AllowedCapabilities {
+		if !util.StringInSlice(string(capability), baselineCapabilities) {
+			return "privileged"
+		}
+	}
+	return "baseline"
+}
+
+// deleteSecurityContextConstraints deletes the SCCs of the Workshop and the Cluster Roles granting them
+func (r *WorkshopReconciler) deleteSecurityContextConstraints(workshop *workshopv1.Workshop) error {
+	// Nothing is created outside of OpenShift
+	if r.Platform != kubernetes.OpenShift {
+		return nil
+	}
+
+	workshopLabels := client.MatchingLabels{kubernetes.WorkshopLabel: kubernetes.WorkshopLabelValue(workshop)}
+
+	sccs := &securityv1.SecurityContextConstraintsList{}
+	if err := r.List(context.TODO(), sccs, workshopLabels); err != nil {
+		return err
+	}
+	for i := range sccs.Items {
+		if err := r.Delete(context.TODO(), &sccs.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Infof("Deleted %s SCC", sccs.Items[i].Name)
+	}
+
+	clusterRoles := &rbac.ClusterRoleList{}
+	if err := r.List(context.TODO(), clusterRoles, workshopLabels); err != nil {
+		return err
+	}
+	for i := range clusterRoles.Items {
+		if err := r.Delete(context.TODO(), &clusterRoles.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Infof("Deleted %s Cluster Role", clusterRoles.Items[i].Name)
+	}
+
+	roleBindings := &rbac.RoleBindingList{}
+	if err := r.List(context.TODO(), roleBindings, workshopLabels); err != nil {
+		return err
+	}
+	for i := range roleBindings.Items {
+		if err := r.Delete(context.TODO(), &roleBindings.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Infof("Deleted %s Role Binding in %s", roleBindings.Items[i].Name, roleBindings.Items[i].Namespace)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/prometheus/common/log"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Phases of a scheduled Workshop
+const (
+	schedulePhaseScheduled    = "Scheduled"
+	schedulePhaseProvisioning = "Provisioning"
+	schedulePhaseRunning      = "Running"
+	schedulePhaseEnded        = "Ended"
+)
+
+// defaultProvisionLeadTime is how long before the start a scheduled Workshop is provisioned
+const defaultProvisionLeadTime = 2 * time.Hour
+
+// reconcileSchedule returns true if the Workshop must be provisioned now,
+// and the result requeuing the Workshop for its next phase
+func (r *WorkshopReconciler) reconcileSchedule(workshop *workshopv1.Workshop, users int,
+	appsHostnameSuffix string) (bool, reconcile.Result, error) {
+	schedule := workshop.Spec.Schedule
+	if schedule.Start == nil && schedule.End == nil {
+		if err := r.updateScheduleStatus(workshop, workshopv1.ScheduleStatus{}); err != nil {
+			return false, reconcile.Result{}, err
+		}
+		return true, reconcile.Result{}, nil
+	}
+
+	status, result := scheduleStatus(schedule, time.Now())
+	if err := r.updateScheduleStatus(workshop, status); err != nil {
+		return false, reconcile.Result{}, err
+	}
+
+	switch status.Phase {
+	case schedulePhaseScheduled:
+		log.Infof("Workshop %s is scheduled, provisioning in %s", workshop.Name, status.TimeRemaining)
+		return false, result, nil
+	case schedulePhaseEnded:
+		endResult, err := r.endWorkshop(workshop, users, appsHostnameSuffix)
+		return false, endResult, err
+	}
+	return true, result, nil
+}
+
+// scheduleStatus returns the phase of the schedule at the given time,
+// and the result requeuing the Workshop when its countdown must be refreshed
+func scheduleStatus(schedule workshopv1.ScheduleSpec, now time.Time) (workshopv1.ScheduleStatus, reconcile.Result) {
+	leadTime := defaultProvisionLeadTime
+	if schedule.ProvisionLeadTime != nil {
+		leadTime = schedule.ProvisionLeadTime.Duration
+	}
+
+	phase := schedulePhaseRunning
+	var nextTransition *metav1.Time
+	switch {
+	case schedule.End != nil && !now.Before(schedule.End.Time):
+		phase = schedulePhaseEnded
+	case schedule.Start != nil && now.Before(schedule.Start.Add(-leadTime)):
+		phase = schedulePhaseScheduled
+		nextTransition = &metav1.Time{Time: schedule.Start.Add(-leadTime)}
+	case schedule.Start != nil && now.Before(schedule.Start.Time):
+		phase = schedulePhaseProvisioning
+		nextTransition = schedule.Start
+	default:
+		nextTransition = schedule.End
+	}
+
+	status := workshopv1.ScheduleStatus{
+		Phase:              phase,
+		NextTransitionTime: nextTransition,
+	}
+	result := reconcile.Result{}
+	if nextTransition != nil {
+		remaining := nextTransition.Sub(now)
+		// Refresh the countdown every minute during the last hour, every hour before
+		granularity := time.Hour
+		if remaining < time.Hour {
+			granularity = time.Minute
+		}
+		status.TimeRemaining = remaining.Round(granularity).String()
+		result.RequeueAfter = remaining % granularity
+		if result.RequeueAfter == 0 {
+			result.RequeueAfter = granularity
+		}
+	}
+
+	return status, result
+}
+
+// endWorkshop runs the end action of the Workshop
+func (r *WorkshopReconciler) endWorkshop(workshop *workshopv1.Workshop, users int,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+
+	switch workshop.Spec.Schedule.EndAction {
+	case "Hibernate":
+		return r.hibernateWorkshop(workshop, users, appsHostnameSuffix)
+	case "Delete":
+		// The finalizer cleans up what cannot be garbage collected
+		if err := r.Delete(context.TODO(), workshop); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Deleted %s Workshop at the end of its schedule", workshop.Name)
+	default:
+		return r.revokeProjectAccess(workshop, users)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// revokeProjectAccess removes the access of the users to their projects and stops the portal
+func (r *WorkshopReconciler) revokeProjectAccess(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
+
+	if workshop.Spec.Infrastructure.Project.Enabled {
+		for id := 1; id <= users; id++ {
+			username := fmt.Sprintf("user%d", id)
+
+			for _, projectName := range userProjectNames(workshop, id) {
+				roleBindingFound := &rbac.RoleBinding{}
+				if err := r.Get(context.TODO(), types.NamespacedName{Name: username + "-project", Namespace: projectName}, roleBindingFound); err != nil {
+					if errors.IsNotFound(err) {
+						continue
+					}
+					return reconcile.Result{}, err
+				}
+				if err := r.Delete(context.TODO(), roleBindingFound); err != nil && !errors.IsNotFound(err) {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Revoked the access of %s to %s", username, projectName)
+			}
+		}
+	}
+
+	deploymentFound := &appsv1.Deployment{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "portal", Namespace: workshop.Namespace}, deploymentFound); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if hibernateReplicas(deploymentFound, &deploymentFound.Spec.Replicas) {
+		if err := r.Update(context.TODO(), deploymentFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Scaled down %s Deployment", deploymentFound.Name)
+	}
+
+	if err := r.updateCondition(workshop, workshopv1.WorkshopProjectAccessRevoked, corev1.ConditionTrue, "Ended",
+		"The users no longer have access to their projects and the portal is stopped"); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// restoreProjectAccess scales the portal back up when the Workshop is provisioned again after its end.
+// The access of the users to their projects is restored with the projects.
+func (r *WorkshopReconciler) restoreProjectAccess(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+
+	if !isConditionTrue(workshop, workshopv1.WorkshopProjectAccessRevoked) {
+		return reconcile.Result{}, nil
+	}
+
+	deploymentFound := &appsv1.Deployment{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "portal", Namespace: workshop.Namespace}, deploymentFound); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	} else if err == nil && restoreReplicas(deploymentFound, &deploymentFound.Spec.Replicas) {
+		if err := r.Update(context.TODO(), deploymentFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Scaled up %s Deployment", deploymentFound.Name)
+	}
+
+	if err := r.updateCondition(workshop, workshopv1.WorkshopProjectAccessRevoked, corev1.ConditionFalse, "Restored",
+		"The users have access to their projects and the portal"); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// updateScheduleStatus sets the schedule status of the Workshop and persists it if it changed
+func (r *WorkshopReconciler) updateScheduleStatus(workshop *workshopv1.Workshop, status workshopv1.ScheduleStatus) error {
+	if reflect.DeepEqual(workshop.Status.Schedule, status) {
+		return nil
+	}
+
+	workshop.Status.Schedule = status
+	return r.Status().Update(context.TODO(), workshop)
+}
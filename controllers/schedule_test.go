@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestScheduleStatus(t *testing.T) {
+	now := time.Date(2026, time.January, 10, 10, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *metav1.Time {
+		return &metav1.Time{Time: now.Add(d)}
+	}
+
+	tests := []struct {
+		name           string
+		schedule       workshopv1.ScheduleSpec
+		phase          string
+		nextTransition *metav1.Time
+		timeRemaining  string
+		requeueAfter   time.Duration
+	}{
+		{
+			name:  "no start nor end",
+			phase: schedulePhaseRunning,
+		},
+		{
+			name:           "before the default lead time",
+			schedule:       workshopv1.ScheduleSpec{Start: at(5 * time.Hour)},
+			phase:          schedulePhaseScheduled,
+			nextTransition: at(3 * time.Hour),
+			timeRemaining:  "3h0m0s",
+			requeueAfter:   time.Hour,
+		},
+		{
+			name:           "countdown rounded to the hour",
+			schedule:       workshopv1.ScheduleSpec{Start: at(3*time.Hour + 30*time.Minute)},
+			phase:          schedulePhaseScheduled,
+			nextTransition: at(time.Hour + 30*time.Minute),
+			timeRemaining:  "2h0m0s",
+			requeueAfter:   30 * time.Minute,
+		},
+		{
+			name:           "custom lead time",
+			schedule:       workshopv1.ScheduleSpec{Start: at(45 * time.Minute), ProvisionLeadTime: &metav1.Duration{Duration: 30 * time.Minute}},
+			phase:          schedulePhaseScheduled,
+			nextTransition: at(15 * time.Minute),
+			timeRemaining:  "15m0s",
+			requeueAfter:   time.Minute,
+		},
+		{
+			name:           "provisioning at the lead time",
+			schedule:       workshopv1.ScheduleSpec{Start: at(2 * time.Hour)},
+			phase:          schedulePhaseProvisioning,
+			nextTransition: at(2 * time.Hour),
+			timeRemaining:  "2h0m0s",
+			requeueAfter:   time.Hour,
+		},
+		{
+			name:           "countdown rounded to the minute during the last hour",
+			schedule:       workshopv1.ScheduleSpec{Start: at(45*time.Minute + 30*time.Second)},
+			phase:          schedulePhaseProvisioning,
+			nextTransition: at(45*time.Minute + 30*time.Second),
+			timeRemaining:  "46m0s",
+			requeueAfter:   30 * time.Second,
+		},
+		{
+			name:           "running at the start",
+			schedule:       workshopv1.ScheduleSpec{Start: at(0), End: at(2 * time.Hour)},
+			phase:          schedulePhaseRunning,
+			nextTransition: at(2 * time.Hour),
+			timeRemaining:  "2h0m0s",
+			requeueAfter:   time.Hour,
+		},
+		{
+			name:     "running without end",
+			schedule: workshopv1.ScheduleSpec{Start: at(-time.Hour)},
+			phase:    schedulePhaseRunning,
+		},
+		{
+			name:     "ended at the end",
+			schedule: workshopv1.ScheduleSpec{Start: at(-time.Hour), End: at(0)},
+			phase:    schedulePhaseEnded,
+		},
+		{
+			name:     "end before the start",
+			schedule: workshopv1.ScheduleSpec{Start: at(5 * time.Hour), End: at(-time.Hour)},
+			phase:    schedulePhaseEnded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, result := scheduleStatus(test.schedule, now)
+			if status.Phase != test.phase {
+				t.Errorf("phase = %s, want %s", status.Phase, test.phase)
+			}
+			if !status.NextTransitionTime.Equal(test.nextTransition) {
+				t.Errorf("next transition = %v, want %v", status.NextTransitionTime, test.nextTransition)
+			}
+			if status.TimeRemaining != test.timeRemaining {
+				t.Errorf("time remaining = %s, want %s", status.TimeRemaining, test.timeRemaining)
+			}
+			if result.RequeueAfter != test.requeueAfter {
+				t.Errorf("requeue after = %s, want %s", result.RequeueAfter, test.requeueAfter)
+			}
+		})
+	}
+}
+
+func TestEndWorkshop(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := workshopv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		endAction        string
+		workshopDeleted  bool
+		accessRevoked    bool
+		hibernated       bool
+		portalAnnotation bool
+	}{
+		{endAction: "", accessRevoked: true, portalAnnotation: true},
+		{endAction: "RevokeProjectAccess", accessRevoked: true, portalAnnotation: true},
+		{endAction: "Hibernate", hibernated: true, portalAnnotation: true},
+		{endAction: "Delete", workshopDeleted: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.endAction, func(t *testing.T) {
+			workshop := &workshopv1.Workshop{
+				ObjectMeta: metav1.ObjectMeta{Name: "workshop", Namespace: "workshops", UID: "uid"},
+				Spec: workshopv1.WorkshopSpec{
+					Schedule: workshopv1.ScheduleSpec{EndAction: test.endAction},
+				},
+			}
+			workshop.Spec.Infrastructure.Project.Enabled = true
+			workshop.Spec.Infrastructure.Project.StagingName = "cn-project"
+
+			replicas := int32(2)
+			portal := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "portal", Namespace: workshop.Namespace},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			}
+			if err := ctrl.SetControllerReference(workshop, portal, scheme); err != nil {
+				t.Fatal(err)
+			}
+			roleBinding := &rbac.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "user1-project", Namespace: "cn-project1"},
+			}
+
+			r := &WorkshopReconciler{
+				Client: fake.NewFakeClientWithScheme(scheme, workshop, portal, roleBinding),
+				Scheme: scheme,
+			}
+
+			if _, err := r.endWorkshop(workshop, 1, "apps.example.com"); err != nil {
+				t.Fatal(err)
+			}
+
+			err := r.Get(context.TODO(), types.NamespacedName{Name: workshop.Name, Namespace: workshop.Namespace}, &workshopv1.Workshop{})
+			if deleted := errors.IsNotFound(err); deleted != test.workshopDeleted {
+				t.Errorf("workshop deleted = %t, want %t", deleted, test.workshopDeleted)
+			}
+
+			err = r.Get(context.TODO(), types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, &rbac.RoleBinding{})
+			if revoked := errors.IsNotFound(err); revoked != test.accessRevoked {
+				t.Errorf("project access revoked = %t, want %t", revoked, test.accessRevoked)
+			}
+			if revoked := isConditionTrue(workshop, workshopv1.WorkshopProjectAccessRevoked); revoked != test.accessRevoked {
+				t.Errorf("ProjectAccessRevoked condition = %t, want %t", revoked, test.accessRevoked)
+			}
+			if hibernated := isHibernated(workshop); hibernated != test.hibernated {
+				t.Errorf("Hibernated condition = %t, want %t", hibernated, test.hibernated)
+			}
+
+			portalFound := &appsv1.Deployment{}
+			if err := r.Get(context.TODO(), types.NamespacedName{Name: portal.Name, Namespace: portal.Namespace}, portalFound); err != nil {
+				t.Fatal(err)
+			}
+			if _, found := portalFound.Annotations[kubernetes.ReplicasAnnotation]; found != test.portalAnnotation {
+				t.Errorf("portal scaled down = %t, want %t", found, test.portalAnnotation)
+			}
+			if !test.accessRevoked {
+				return
+			}
+
+			// The portal is restored when the Workshop is provisioned again
+			if _, err := r.restoreProjectAccess(workshop); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Get(context.TODO(), types.NamespacedName{Name: portal.Name, Namespace: portal.Namespace}, portalFound); err != nil {
+				t.Fatal(err)
+			}
+			if portalFound.Spec.Replicas == nil || *portalFound.Spec.Replicas != replicas {
+				t.Errorf("portal replicas = %v, want %d", portalFound.Spec.Replicas, replicas)
+			}
+			if isConditionTrue(workshop, workshopv1.WorkshopProjectAccessRevoked) {
+				t.Errorf("ProjectAccessRevoked condition is still true")
+			}
+		})
+	}
+}
+
+func TestIsConditionTrue(t *testing.T) {
+	workshop := &workshopv1.Workshop{
+		Status: workshopv1.WorkshopStatus{
+			Conditions: []workshopv1.WorkshopCondition{
+				{Type: workshopv1.WorkshopHibernated, Status: corev1.ConditionFalse},
+				{Type: workshopv1.WorkshopProjectAccessRevoked, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+
+	if isConditionTrue(workshop, workshopv1.WorkshopHibernated) {
+		t.Errorf("false condition reported true")
+	}
+	if !isConditionTrue(workshop, workshopv1.WorkshopProjectAccessRevoked) {
+		t.Errorf("true condition reported false")
+	}
+	if isConditionTrue(workshop, workshopv1.WorkshopClusterDiscovered) {
+		t.Errorf("missing condition reported true")
+	}
+}
@@ -0,0 +1,400 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/kubernetes"
+	"github.com/mcouliba/workshop-operator/common/util"
+	"github.com/prometheus/common/log"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// seedRefreshInterval is how often the commit of the seed manifests fetched from git is checked
+const seedRefreshInterval = time.Minute * 10
+
+// seedHTTPClient fetches the seed manifests, with the TLS certificates verified
+var seedHTTPClient = &http.Client{
+	Timeout: time.Second * 30,
+}
+
+// seedTemplateData holds the values available in the seed manifests
+type seedTemplateData struct {
+	Username           string
+	UserID             int
+	Project            string
+	AppsHostnameSuffix string
+	GiteaURL           string
+}
+
+// seedManifests are the seed manifests fetched from a directory of a git repository at a commit
+type seedManifests struct {
+	source    string
+	sha       string
+	checkedAt time.Time
+	manifests map[string]string
+}
+
+// Reconciling Seed
+func (r *WorkshopReconciler) reconcileSeed(workshop *workshopv1.Workshop, users int,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+
+	seed := workshop.Spec.Infrastructure.Project.Seed
+	if !workshop.Spec.Infrastructure.Project.Enabled || !seed.Enabled {
+		// Nothing is rendered anymore
+		return reconcile.Result{}, r.pruneSeedObjects(workshop, map[string]bool{}, map[string]bool{})
+	}
+
+	var (
+		manifests map[string]string
+		result    reconcile.Result
+		err       error
+	)
+	if seed.ConfigMap != "" {
+		manifests, result, err = r.getSeedManifestsFromConfigMap(workshop, seed.ConfigMap)
+	} else {
+		// The manifests are fetched through the GitHub API
+		if _, err := githubRepositoryURL(workshop); err != nil {
+			log.Warnf("Skipping the seed manifests: %v", err)
+			return reconcile.Result{}, r.updateCondition(workshop, workshopv1.WorkshopSeedSourceSupported,
+				corev1.ConditionFalse, "Unsupported", err.Error())
+		}
+		if err := r.updateCondition(workshop, workshopv1.WorkshopSeedSourceSupported, corev1.ConditionTrue, "Supported", ""); err != nil {
+			return reconcile.Result{}, err
+		}
+		manifests, result, err = r.getCachedSeedManifestsFromGit(workshop, seed.Path)
+	}
+	if util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	// Manifests are applied in the order of their names
+	names := []string{}
+	for name := range manifests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	giteaURL := ""
+	if workshop.Spec.Infrastructure.Gitea.Enabled {
+		giteaURL = "https://gitea-server-" + workshop.Status.Namespaces.Gitea + "." + appsHostnameSuffix
+	}
+
+	labels := seedLabels(workshop)
+	kinds := map[string]bool{}
+	applied := map[string]bool{}
+
+	for id := 1; id <= users; id++ {
+		username := fmt.Sprintf("user%d", id)
+
+		for _, projectName := range userProjectNames(workshop, id) {
+			data := seedTemplateData{
+				Username:           username,
+				UserID:             id,
+				Project:            projectName,
+				AppsHostnameSuffix: appsHostnameSuffix,
+				GiteaURL:           giteaURL,
+			}
+
+			for _, name := range names {
+				objs, err := kubernetes.NewUnstructuredListFromTemplate(workshop, r.Scheme, manifests[name], projectName, labels, data)
+				if err != nil {
+					log.Errorf("Error when rendering %s seed manifest for %s: %v", name, projectName, err)
+					return reconcile.Result{}, err
+				}
+				for _, obj := range objs {
+					// The manifests are applied with the privileges of the operator,
+					// they must not reach out of the project
+					if err := r.checkNamespaced(obj); err != nil {
+						log.Errorf("Rejected %s seed manifest: %v", name, err)
+						return reconcile.Result{}, err
+					}
+					if err := r.applyUnstructured(obj); err != nil {
+						log.Errorf("Error when applying %s seed manifest in %s: %v", name, projectName, err)
+						return reconcile.Result{}, err
+					}
+					kinds[obj.GetAPIVersion()+"/"+obj.GetKind()] = true
+					applied[seedObjectKey(obj)] = true
+				}
+			}
+		}
+	}
+
+	if err := r.pruneSeedObjects(workshop, kinds, applied); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if seed.ConfigMap == "" {
+		return reconcile.Result{RequeueAfter: seedRefreshInterval}, nil
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// seedLabels identifies the seed objects of the Workshop
+func seedLabels(workshop *workshopv1.Workshop) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/part-of": "seed",
+		kubernetes.WorkshopLabel:    kubernetes.WorkshopLabelValue(workshop),
+	}
+}
+
+// seedObjectKey identifies a seed object among the ones of the Workshop
+func seedObjectKey(obj *unstructured.Unstructured) string {
+	return obj.GetAPIVersion() + "/" + obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// pruneSeedObjects deletes the seed objects of the Workshop which were not applied,
+// looking for them among the kinds applied now and at the previous reconciliation
+func (r *WorkshopReconciler) pruneSeedObjects(workshop *workshopv1.Workshop, kinds map[string]bool, applied map[string]bool) error {
+	prunedKinds := map[string]bool{}
+	for kind := range kinds {
+		prunedKinds[kind] = true
+	}
+	for _, kind := range workshop.Status.SeedKinds {
+		prunedKinds[kind] = true
+	}
+
+	for kind := range prunedKinds {
+		separator := strings.LastIndex(kind, "/")
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.FromAPIVersionAndKind(kind[:separator], kind[separator+1:]+"List"))
+		if err := r.List(context.TODO(), list, client.MatchingLabels(seedLabels(workshop))); err != nil {
+			// The kind is no longer served
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if applied[seedObjectKey(obj)] {
+				continue
+			}
+			if err := r.Delete(context.TODO(), obj); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			log.Infof("Deleted %s %s seed object in %s", obj.GetName(), obj.GetKind(), obj.GetNamespace())
+		}
+	}
+
+	seedKinds := []string{}
+	for kind := range kinds {
+		seedKinds = append(seedKinds, kind)
+	}
+	sort.Strings(seedKinds)
+	if len(seedKinds) == 0 && len(workshop.Status.SeedKinds) == 0 ||
+		reflect.DeepEqual(seedKinds, workshop.Status.SeedKinds) {
+		return nil
+	}
+
+	workshop.Status.SeedKinds = seedKinds
+	return r.Status().Update(context.TODO(), workshop)
+}
+
+// checkNamespaced returns an error if the kind of the object is not namespaced
+func (r *WorkshopReconciler) checkNamespaced(obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+	mapping, err := r.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return fmt.Errorf("%s %s is not namespaced", obj.GetName(), gvk.Kind)
+	}
+	return nil
+}
+
+// getSeedManifestsFromConfigMap returns the manifests held by the ConfigMap in the namespace of the Workshop
+func (r *WorkshopReconciler) getSeedManifestsFromConfigMap(workshop *workshopv1.Workshop,
+	name string) (map[string]string, reconcile.Result, error) {
+
+	configMapFound := &corev1.ConfigMap{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: workshop.Namespace}, configMapFound); err != nil {
+		if errors.IsNotFound(err) {
+			log.Warnf("Waiting for %s ConfigMap holding the seed manifests", name)
+			return nil, reconcile.Result{Requeue: true}, nil
+		}
+		return nil, reconcile.Result{}, err
+	}
+
+	return configMapFound.Data, reconcile.Result{}, nil
+}
+
+// getCachedSeedManifestsFromGit returns the manifests fetched from git for the Workshop.
+// The commit of the branch is checked every seedRefreshInterval and the manifests are only
+// fetched again when it changed.
+func (r *WorkshopReconciler) getCachedSeedManifestsFromGit(workshop *workshopv1.Workshop,
+	directory string) (map[string]string, reconcile.Result, error) {
+
+	r.seedCacheMutex.Lock()
+	defer r.seedCacheMutex.Unlock()
+
+	key := workshopKey(workshop)
+	source := workshop.Spec.Source.GitURL + "#" + workshop.Spec.Source.GitBranch + ":" + directory
+	cached, found := r.seedCache[key]
+	if found && cached.source != source {
+		found = false
+	}
+	if found && time.Since(cached.checkedAt) < seedRefreshInterval {
+		return cached.manifests, reconcile.Result{}, nil
+	}
+
+	repositoryURL, err := githubRepositoryURL(workshop)
+	if err != nil {
+		return nil, reconcile.Result{}, err
+	}
+
+	sha, result, err := getGitHubCommitSHA(repositoryURL, workshop.Spec.Source.GitBranch)
+	if util.IsRequeued(result, err) {
+		return nil, result, err
+	}
+
+	if !found || cached.sha != sha {
+		manifests, result, err := getSeedManifestsFromGitHub(repositoryURL, directory, sha)
+		if util.IsRequeued(result, err) {
+			return nil, result, err
+		}
+		cached = seedManifests{source: source, sha: sha, manifests: manifests}
+		log.Infof("Fetched %d seed manifest(s) at commit %s", len(manifests), sha)
+	}
+	cached.checkedAt = time.Now()
+
+	if r.seedCache == nil {
+		r.seedCache = map[string]seedManifests{}
+	}
+	r.seedCache[key] = cached
+
+	return cached.manifests, reconcile.Result{}, nil
+}
+
+// githubRepositoryURL returns the GitHub API URL of the repository of the Workshop
+func githubRepositoryURL(workshop *workshopv1.Workshop) (string, error) {
+	gitURL, err := url.Parse(workshop.Spec.Source.GitURL)
+	if err != nil {
+		return "", err
+	}
+	if gitURL.Host != "github.com" {
+		return "", fmt.Errorf("seed manifests can only be fetched from GitHub, not from %s", gitURL.Host)
+	}
+	return "https://api.github.com/repos" + strings.TrimSuffix(gitURL.Path, ".git"), nil
+}
+
+// getGitHubCommitSHA returns the SHA of the commit of a branch
+func getGitHubCommitSHA(repositoryURL string, branch string) (string, reconcile.Result, error) {
+
+	commitURL := repositoryURL + "/commits/" + url.PathEscape(branch)
+
+	httpRequest, err := http.NewRequest("GET", commitURL, nil)
+	if err != nil {
+		return "", reconcile.Result{}, err
+	}
+	httpRequest.Header.Set("Accept", "application/vnd.github.v3.sha")
+
+	httpResponse, err := seedHTTPClient.Do(httpRequest)
+	if err != nil {
+		log.Errorf("Error when getting the commit of the seed manifests from %s", commitURL)
+		return "", reconcile.Result{}, err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		log.Errorf("Error (%v) when getting the commit of the seed manifests from %s", httpResponse.StatusCode, commitURL)
+		return "", reconcile.Result{Requeue: true, RequeueAfter: time.Minute}, nil
+	}
+	bodyBytes, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		log.Errorf("Error when reading the commit of the seed manifests from %s", commitURL)
+		return "", reconcile.Result{}, err
+	}
+
+	return strings.TrimSpace(string(bodyBytes)), reconcile.Result{}, nil
+}
+
+// getSeedManifestsFromGitHub returns the YAML and JSON manifests of a directory of a GitHub repository at a commit
+func getSeedManifestsFromGitHub(repositoryURL string, directory string, sha string) (map[string]string, reconcile.Result, error) {
+
+	var (
+		httpResponse *http.Response
+		httpRequest  *http.Request
+		files        []struct {
+			Name        string `json:"name"`
+			Type        string `json:"type"`
+			DownloadURL string `json:"download_url"`
+		}
+		manifests = map[string]string{}
+	)
+
+	contentsURL := fmt.Sprintf("%s/contents/%s?ref=%s", repositoryURL, strings.Trim(directory, "/"), sha)
+
+	// LIST FILES
+	httpRequest, err := http.NewRequest("GET", contentsURL, nil)
+	if err != nil {
+		return nil, reconcile.Result{}, err
+	}
+	httpRequest.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	httpResponse, err = seedHTTPClient.Do(httpRequest)
+	if err != nil {
+		log.Errorf("Error when listing the seed manifests from %s", contentsURL)
+		return nil, reconcile.Result{}, err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		log.Errorf("Error (%v) when listing the seed manifests from %s", httpResponse.StatusCode, contentsURL)
+		return nil, reconcile.Result{Requeue: true, RequeueAfter: time.Minute}, nil
+	}
+	if err := json.NewDecoder(httpResponse.Body).Decode(&files); err != nil {
+		log.Errorf("Error when reading the seed manifests list from %s", contentsURL)
+		return nil, reconcile.Result{}, err
+	}
+
+	// GET FILES
+	for _, file := range files {
+		extension := path.Ext(file.Name)
+		if file.Type != "file" || (extension != ".yaml" && extension != ".yml" && extension != ".json") {
+			continue
+		}
+
+		httpRequest, err = http.NewRequest("GET", file.DownloadURL, nil)
+		if err != nil {
+			return nil, reconcile.Result{}, err
+		}
+		fileResponse, err := seedHTTPClient.Do(httpRequest)
+		if err != nil {
+			log.Errorf("Error when getting the seed manifest from %s", file.DownloadURL)
+			return nil, reconcile.Result{}, err
+		}
+		bodyBytes, err := ioutil.ReadAll(fileResponse.Body)
+		fileResponse.Body.Close()
+		if err != nil {
+			log.Errorf("Error when reading %s", file.DownloadURL)
+			return nil, reconcile.Result{}, err
+		}
+		if fileResponse.StatusCode != http.StatusOK {
+			log.Errorf("Error (%v) when getting the seed manifest from %s", fileResponse.StatusCode, file.DownloadURL)
+			return nil, reconcile.Result{Requeue: true, RequeueAfter: time.Minute}, nil
+		}
+		manifests[file.Name] = string(bodyBytes)
+	}
+
+	return manifests, reconcile.Result{}, nil
+}
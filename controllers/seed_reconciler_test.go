@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPruneSeedObjects(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := workshopv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	workshop := &workshopv1.Workshop{
+		ObjectMeta: metav1.ObjectMeta{Name: "workshop", Namespace: "workshops"},
+		Status:     workshopv1.WorkshopStatus{SeedKinds: []string{"v1/ConfigMap"}},
+	}
+	otherWorkshop := &workshopv1.Workshop{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "workshops"},
+	}
+
+	configMap := func(name string, labels map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "cn-project1", Labels: labels},
+		}
+	}
+	rendered := configMap("rendered", seedLabels(workshop))
+	removed := configMap("removed", seedLabels(workshop))
+	unlabeled := configMap("unlabeled", nil)
+	otherSeed := configMap("other-seed", seedLabels(otherWorkshop))
+
+	r := &WorkshopReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme, workshop, rendered, removed, unlabeled, otherSeed),
+		Scheme: scheme,
+	}
+
+	renderedObj := &unstructured.Unstructured{}
+	renderedObj.SetAPIVersion("v1")
+	renderedObj.SetKind("ConfigMap")
+	renderedObj.SetNamespace(rendered.Namespace)
+	renderedObj.SetName(rendered.Name)
+
+	if err := r.pruneSeedObjects(workshop, map[string]bool{"v1/ConfigMap": true},
+		map[string]bool{seedObjectKey(renderedObj): true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		configMap *corev1.ConfigMap
+		deleted   bool
+	}{
+		{configMap: rendered, deleted: false},
+		{configMap: removed, deleted: true},
+		{configMap: unlabeled, deleted: false},
+		{configMap: otherSeed, deleted: false},
+	} {
+		err := r.Get(context.TODO(), types.NamespacedName{Name: test.configMap.Name, Namespace: test.configMap.Namespace}, &corev1.ConfigMap{})
+		if deleted := errors.IsNotFound(err); deleted != test.deleted {
+			t.Errorf("%s deleted = %t, want %t", test.configMap.Name, deleted, test.deleted)
+		}
+	}
+
+	// Disabling the seed deletes the remaining objects and forgets their kinds
+	if err := r.pruneSeedObjects(workshop, map[string]bool{}, map[string]bool{}); err != nil {
+		t.Fatal(err)
+	}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: rendered.Name, Namespace: rendered.Namespace}, &corev1.ConfigMap{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("rendered was not deleted with the seed disabled: %v", err)
+	}
+	if len(workshop.Status.SeedKinds) != 0 {
+		t.Errorf("seed kinds = %v, want none", workshop.Status.SeedKinds)
+	}
+}
+
+func TestGitHubRepositoryURL(t *testing.T) {
+	tests := []struct {
+		gitURL        string
+		repositoryURL string
+		err           bool
+	}{
+		{gitURL: "https://github.com/mcouliba/cloud-native-workshop", repositoryURL: "https://api.github.com/repos/mcouliba/cloud-native-workshop"},
+		{gitURL: "https://github.com/mcouliba/cloud-native-workshop.git", repositoryURL: "https://api.github.com/repos/mcouliba/cloud-native-workshop"},
+		{gitURL: "https://gitlab.com/mcouliba/cloud-native-workshop.git", err: true},
+		{gitURL: "https://gitea-server-gitea.apps.example.com/user1/cloud-native-workshop", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.gitURL, func(t *testing.T) {
+			workshop := &workshopv1.Workshop{}
+			workshop.Spec.Source.GitURL = test.gitURL
+
+			repositoryURL, err := githubRepositoryURL(workshop)
+			if (err != nil) != test.err {
+				t.Fatalf("error = %v, want error %t", err, test.err)
+			}
+			if repositoryURL != test.repositoryURL {
+				t.Errorf("repository URL = %q, want %q", repositoryURL, test.repositoryURL)
+			}
+		})
+	}
+}
@@ -17,6 +17,10 @@ func (r *WorkshopReconciler) reconcileServerless(workshop *workshopv1.Workshop)
 	enabledServerless := workshop.Spec.Infrastructure.Serverless.Enabled
 
 	if enabledServerless {
+		// Knative Serving relies on the control plane of OpenShift Service Mesh
+		if unsupported, err := r.isOpenShiftOnly(workshop, workshopv1.WorkshopServerlessSupported, "OpenShift Serverless", true); unsupported || err != nil {
+			return reconcile.Result{}, err
+		}
 
 		if result, err := r.addServerless(workshop); util.IsRequeued(result, err) {
 			return result, err
@@ -32,12 +36,15 @@ func (r *WorkshopReconciler) addServerless(workshop *workshopv1.Workshop) (recon
 	channel := workshop.Spec.Infrastructure.Serverless.OperatorHub.Channel
 	clusterServiceVersion := workshop.Spec.Infrastructure.Serverless.OperatorHub.ClusterServiceVersion
 
-	namespace := kubernetes.NewNamespace(workshop, r.Scheme, "openshift-serverless")
+	namespace := kubernetes.NewNamespace(workshop, r.Scheme, workshop.Status.Namespaces.Serverless)
 	if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Project", namespace.Name)
 	}
+	if err := r.acquireSharedNamespace(workshop, namespace); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, "serverless-operator", namespace.Name, "serverless-operator",
 		channel, clusterServiceVersion)
@@ -46,13 +53,19 @@ func (r *WorkshopReconciler) addServerless(workshop *workshopv1.Workshop) (recon
 	} else if err == nil {
 		log.Infof("Created %s Subscription", subscription.Name)
 	}
+	if err := r.acquireShared(workshop, subscription); err != nil {
+		return reconcile.Result{}, err
+	}
 
-	knativeServingNamespace := kubernetes.NewNamespace(workshop, r.Scheme, "knative-serving")
+	knativeServingNamespace := kubernetes.NewNamespace(workshop, r.Scheme, workshop.Status.Namespaces.KnativeServing)
 	if err := r.Create(context.TODO(), knativeServingNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Namespace", knativeServingNamespace.Name)
 	}
+	if err := r.acquireSharedNamespace(workshop, knativeServingNamespace); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	knativeEventingNamespace := kubernetes.NewNamespace(workshop, r.Scheme, "knative-eventing")
 	if err := r.Create(context.TODO(), knativeEventingNamespace); err != nil && !errors.IsAlreadyExists(err) {
@@ -60,10 +73,18 @@ func (r *WorkshopReconciler) addServerless(workshop *workshopv1.Workshop) (recon
 	} else if err == nil {
 		log.Infof("Created %s Namespace", knativeEventingNamespace.Name)
 	}
+	if err := r.acquireSharedNamespace(workshop, knativeEventingNamespace); err != nil {
+		return reconcile.Result{}, err
+	}
 
-	// TODO
-	// Add  knativeServingNamespace to ServiceMeshMember
-	// Create CR
+	// Add knative-serving to the Service Mesh
+	labels := map[string]string{
+		"app.kubernetes.io/part-of": "serverless",
+	}
+	if result, err := r.addServiceMeshMember(workshop, knativeServingNamespace.Name, labels,
+		"basic", workshop.Status.Namespaces.ServiceMesh); util.IsRequeued(result, err) {
+		return result, err
+	}
 
 	//Success
 	return reconcile.Result{}, nil
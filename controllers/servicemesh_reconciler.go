@@ -6,24 +6,35 @@ import (
 	"reflect"
 
 	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/argocd"
 	"github.com/mcouliba/workshop-operator/common/kubernetes"
 	"github.com/mcouliba/workshop-operator/common/maistra"
 	"github.com/mcouliba/workshop-operator/common/util"
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/prometheus/common/log"
 
+	corev1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // Reconciling ServiceMesh
-func (r *WorkshopReconciler) reconcileServiceMesh(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
+func (r *WorkshopReconciler) reconcileServiceMesh(workshop *workshopv1.Workshop, users int,
+	appsHostnameSuffix string) (reconcile.Result, error) {
 	enabledServiceMesh := workshop.Spec.Infrastructure.ServiceMesh.Enabled
 	enabledServerless := workshop.Spec.Infrastructure.Serverless.Enabled
 
 	if enabledServiceMesh || enabledServerless {
+		// The Maistra control plane is only shipped by OpenShift Service Mesh
+		if unsupported, err := r.isOpenShiftOnly(workshop, workshopv1.WorkshopServiceMeshSupported, "OpenShift Service Mesh", true); unsupported || err != nil {
+			return reconcile.Result{}, err
+		}
 
 		if result, err := r.addElasticSearchOperator(workshop); util.IsRequeued(result, err) {
 			return result, err
@@ -40,6 +51,23 @@ func (r *WorkshopReconciler) reconcileServiceMesh(workshop *workshopv1.Workshop,
 		if result, err := r.addServiceMesh(workshop, users); util.IsRequeued(result, err) {
 			return result, err
 		}
+
+		if result, err := r.deleteUserServiceMeshes(workshop, users); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	if enabledServiceMesh && workshop.Spec.Infrastructure.ServiceMesh.UserRouting.Enabled &&
+		workshop.Spec.Infrastructure.Project.Enabled {
+		for id := 1; id <= users; id++ {
+			username := fmt.Sprintf("user%d", id)
+
+			for _, projectName := range userMeshProjectNames(workshop, id) {
+				if result, err := r.addServiceMeshUserRouting(workshop, username, projectName, appsHostnameSuffix); util.IsRequeued(result, err) {
+					return result, err
+				}
+			}
+		}
 	}
 
 	//Success
@@ -48,7 +76,7 @@ func (r *WorkshopReconciler) reconcileServiceMesh(workshop *workshopv1.Workshop,
 
 func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
 
-	operatorNamespace := "openshift-operators"
+	operatorNamespace := r.operatorsNamespace()
 
 	// Service Mesh Operator
 	channel := workshop.Spec.Infrastructure.ServiceMesh.ServiceMeshOperatorHub.Channel
@@ -61,6 +89,9 @@ func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users
 	} else if err == nil {
 		log.Infof("Created %s Subscription", subscription.Name)
 	}
+	if err := r.acquireShared(workshop, subscription); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	if err := r.ApproveInstallPlan(clusterserviceversion, "servicemeshoperator", operatorNamespace); err != nil {
 		log.Infof("Waiting for Subscription to create InstallPlan for %s", subscription.Name)
@@ -72,21 +103,42 @@ func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	perUserTenancy := workshop.Spec.Infrastructure.ServiceMesh.Enabled &&
+		workshop.Spec.Infrastructure.ServiceMesh.Tenancy == "PerUser"
+
+	if perUserTenancy {
+		for id := 1; id <= users; id++ {
+			username := fmt.Sprintf("user%d", id)
+
+			if result, err := r.addUserServiceMesh(workshop, username, userMeshProjectNames(workshop, id)); util.IsRequeued(result, err) {
+				return result, err
+			}
+		}
+
+		// Serverless still relies on the shared control plane
+		if !workshop.Spec.Infrastructure.Serverless.Enabled {
+			return reconcile.Result{}, nil
+		}
+	}
+
 	// Deploy Service Mesh
-	istioSystemNamespace := kubernetes.NewNamespace(workshop, r.Scheme, "istio-system")
+	istioSystemNamespace := kubernetes.NewNamespace(workshop, r.Scheme, workshop.Status.Namespaces.ServiceMesh)
 	if err := r.Create(context.TODO(), istioSystemNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Namespace", istioSystemNamespace.Name)
 	}
+	if err := r.acquireSharedNamespace(workshop, istioSystemNamespace); err != nil {
+		return reconcile.Result{}, err
+	}
 
-	istioMembers := []string{}
+	istioMembers := map[string]bool{}
 	istioUsers := []rbac.Subject{}
 
 	if workshop.Spec.Infrastructure.GitOps.Enabled {
 		argocdSubject := rbac.Subject{
 			Kind:     rbac.UserKind,
-			Name:     "system:serviceaccount:argocd:argocd-argocd-application-controller",
+			Name:     argocd.ApplicationControllerUser(argocdInstance(workshop)),
 			APIGroup: "rbac.authorization.k8s.io",
 		}
 		istioUsers = append(istioUsers, argocdSubject)
@@ -94,14 +146,22 @@ func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users
 
 	for id := 1; id <= users; id++ {
 		username := fmt.Sprintf("user%d", id)
-		stagingProjectName := fmt.Sprintf("%s%d", workshop.Spec.Infrastructure.Project.StagingName, id)
 		userSubject := rbac.Subject{
 			Kind:     rbac.UserKind,
 			Name:     username,
 			APIGroup: "rbac.authorization.k8s.io",
 		}
 
-		istioMembers = append(istioMembers, stagingProjectName)
+		// With a control plane per user, the projects and users belong to their own control plane
+		if perUserTenancy {
+			continue
+		}
+
+		if workshop.Spec.Infrastructure.Project.Enabled {
+			for _, projectName := range userMeshProjectNames(workshop, id) {
+				istioMembers[projectName] = true
+			}
+		}
 		istioUsers = append(istioUsers, userSubject)
 	}
 
@@ -110,15 +170,19 @@ func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users
 	}
 
 	jaegerRole := kubernetes.NewRole(workshop, r.Scheme,
-		"jaeger-user", "istio-system", labels, kubernetes.JaegerUserRules())
+		"jaeger-user", workshop.Status.Namespaces.ServiceMesh, labels, kubernetes.JaegerUserRules())
 	if err := r.Create(context.TODO(), jaegerRole); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Role", jaegerRole.Name)
 	}
+	if err := r.acquireShared(workshop, jaegerRole); err != nil {
+		return reconcile.Result{}, err
+	}
 
+	// The control plane is shared by the Workshops, each one binds its own users
 	jaegerRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
-		"jaeger-users", "istio-system", labels, istioUsers, jaegerRole.Name, "Role")
+		kubernetes.PrefixedName(workshop, "jaeger-users"), workshop.Status.Namespaces.ServiceMesh, labels, istioUsers, jaegerRole.Name, "Role")
 	if err := r.Create(context.TODO(), jaegerRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
@@ -139,7 +203,7 @@ func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users
 	}
 
 	meshUserRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
-		"mesh-users", "istio-system", labels, istioUsers, "mesh-user", "Role")
+		kubernetes.PrefixedName(workshop, "mesh-users"), workshop.Status.Namespaces.ServiceMesh, labels, istioUsers, "mesh-user", "Role")
 
 	if err := r.Create(context.TODO(), meshUserRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
@@ -148,29 +212,276 @@ func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users
 	}
 
 	serviceMeshControlPlaneCR := maistra.NewServiceMeshControlPlaneCR(workshop, r.Scheme, "basic", istioSystemNamespace.Name)
-	if err := r.Create(context.TODO(), serviceMeshControlPlaneCR); err != nil && !errors.IsAlreadyExists(err) {
+	if result, err := r.addServiceMeshControlPlane(serviceMeshControlPlaneCR); util.IsRequeued(result, err) {
+		return result, err
+	}
+	if err := r.acquireShared(workshop, serviceMeshControlPlaneCR); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The Member Roll is filled by the Service Mesh Operator from the ServiceMeshMember objects
+	serviceMeshMemberRollCR := maistra.NewServiceMeshMemberRollCR(workshop, r.Scheme,
+		"default", istioSystemNamespace.Name, []string{})
+	if err := r.Create(context.TODO(), serviceMeshMemberRollCR); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Service Mesh Control Plane Custom Resource", serviceMeshControlPlaneCR.Name)
+		log.Infof("Created %s Service Mesh Member Roll Custom Resource", serviceMeshMemberRollCR.Name)
+	}
+	if err := r.acquireShared(workshop, serviceMeshMemberRollCR); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	memberLabels := map[string]string{
+		"app.kubernetes.io/part-of":   "istio",
+		"app.kubernetes.io/component": "staging-project",
+		kubernetes.WorkshopLabel:      kubernetes.WorkshopLabelValue(workshop),
+	}
+
+	for member := range istioMembers {
+		if result, err := r.addServiceMeshMember(workshop, member, memberLabels,
+			serviceMeshControlPlaneCR.Name, istioSystemNamespace.Name); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	// Remove the members of projects which are no longer part of the workshop
+	serviceMeshMemberList := &maistrav1.ServiceMeshMemberList{}
+	if err := r.List(context.TODO(), serviceMeshMemberList, client.MatchingLabels(memberLabels)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range serviceMeshMemberList.Items {
+		serviceMeshMember := &serviceMeshMemberList.Items[i]
+		if istioMembers[serviceMeshMember.Namespace] ||
+			serviceMeshMember.Spec.ControlPlaneRef.Namespace != istioSystemNamespace.Name {
+			continue
+		}
+		if err := r.Delete(context.TODO(), serviceMeshMember); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Deleted %s Service Mesh Member Custom Resource in %s", serviceMeshMember.Name, serviceMeshMember.Namespace)
+	}
+
+	// Drop the user projects previously written in the Member Roll
+	// and leave the entries added by other components or administrators
+	serviceMeshMemberRollCRFound := &maistrav1.ServiceMeshMemberRoll{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: serviceMeshMemberRollCR.Name, Namespace: istioSystemNamespace.Name}, serviceMeshMemberRollCRFound); err != nil {
+		return reconcile.Result{}, err
+	}
+	members := []string{}
+	for _, member := range serviceMeshMemberRollCRFound.Spec.Members {
+		if !istioMembers[member] && isUserProject(workshop, member) {
+			continue
+		}
+		members = append(members, member)
+	}
+	if len(members) != len(serviceMeshMemberRollCRFound.Spec.Members) {
+		serviceMeshMemberRollCRFound.Spec.Members = members
+		if err := r.Update(context.TODO(), serviceMeshMemberRollCRFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Updated %s Service Mesh Member Roll Custom Resource", serviceMeshMemberRollCRFound.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func (r *WorkshopReconciler) addUserServiceMesh(workshop *workshopv1.Workshop, username string,
+	projectNames []string) (reconcile.Result, error) {
+
+	controlPlaneNamespace := kubernetes.NewNamespace(workshop, r.Scheme, serviceMeshControlPlaneNamespace(workshop, username))
+	controlPlaneNamespace.Labels = userControlPlaneLabels(workshop)
+	if err := r.Create(context.TODO(), controlPlaneNamespace); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Namespace", controlPlaneNamespace.Name)
+	}
+
+	labels := map[string]string{
+		"app.kubernetes.io/part-of": "istio",
+		"app.kubernetes.io/name":    username,
+	}
+
+	istioUsers := []rbac.Subject{
+		{
+			Kind:     rbac.UserKind,
+			Name:     username,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+	}
+
+	if workshop.Spec.Infrastructure.GitOps.Enabled {
+		argocdSubject := rbac.Subject{
+			Kind:     rbac.UserKind,
+			Name:     argocd.ApplicationControllerUser(argocdInstance(workshop)),
+			APIGroup: "rbac.authorization.k8s.io",
+		}
+		istioUsers = append(istioUsers, argocdSubject)
+	}
+
+	jaegerRole := kubernetes.NewRole(workshop, r.Scheme,
+		"jaeger-user", controlPlaneNamespace.Name, labels, kubernetes.JaegerUserRules())
+	if err := r.Create(context.TODO(), jaegerRole); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Role in %s", jaegerRole.Name, controlPlaneNamespace.Name)
+	}
+
+	jaegerRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
+		"jaeger-users", controlPlaneNamespace.Name, labels, istioUsers, jaegerRole.Name, "Role")
+	if err := r.Create(context.TODO(), jaegerRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Role Binding in %s", jaegerRoleBinding.Name, controlPlaneNamespace.Name)
+	}
+
+	meshUserRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
+		"mesh-users", controlPlaneNamespace.Name, labels, istioUsers, "mesh-user", "Role")
+	if err := r.Create(context.TODO(), meshUserRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Role Binding in %s", meshUserRoleBinding.Name, controlPlaneNamespace.Name)
+	}
+
+	// Kiali relies on the user permissions on the control plane namespace
+	viewRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
+		"mesh-viewers", controlPlaneNamespace.Name, labels, istioUsers, "view", "ClusterRole")
+	if err := r.Create(context.TODO(), viewRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Role Binding in %s", viewRoleBinding.Name, controlPlaneNamespace.Name)
+	}
+
+	serviceMeshControlPlaneCR := maistra.NewUserServiceMeshControlPlaneCR(workshop, r.Scheme, "basic", controlPlaneNamespace.Name)
+	if result, err := r.addServiceMeshControlPlane(serviceMeshControlPlaneCR); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	serviceMeshMemberRollCR := maistra.NewServiceMeshMemberRollCR(workshop, r.Scheme,
-		"default", istioSystemNamespace.Name, istioMembers)
+		"default", controlPlaneNamespace.Name, []string{})
 	if err := r.Create(context.TODO(), serviceMeshMemberRollCR); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Custom Resource", serviceMeshMemberRollCR.Name)
+		log.Infof("Created %s Service Mesh Member Roll Custom Resource in %s", serviceMeshMemberRollCR.Name, controlPlaneNamespace.Name)
+	}
+
+	if workshop.Spec.Infrastructure.Project.Enabled {
+		memberLabels := map[string]string{
+			"app.kubernetes.io/part-of":   "istio",
+			"app.kubernetes.io/component": "staging-project",
+			kubernetes.WorkshopLabel:      kubernetes.WorkshopLabelValue(workshop),
+		}
+		for _, projectName := range projectNames {
+			if result, err := r.addServiceMeshMember(workshop, projectName, memberLabels,
+				serviceMeshControlPlaneCR.Name, controlPlaneNamespace.Name); util.IsRequeued(result, err) {
+				return result, err
+			}
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// deleteUserServiceMeshes deletes the control planes of the users who left the workshop,
+// or all of them when the tenancy is no longer per user
+func (r *WorkshopReconciler) deleteUserServiceMeshes(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
+	userNamespaces := map[string]bool{}
+	if workshop.Spec.Infrastructure.ServiceMesh.Enabled && workshop.Spec.Infrastructure.ServiceMesh.Tenancy == "PerUser" {
+		for id := 1; id <= users; id++ {
+			userNamespaces[serviceMeshControlPlaneNamespace(workshop, fmt.Sprintf("user%d", id))] = true
+		}
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.List(context.TODO(), namespaceList, client.MatchingLabels(userControlPlaneLabels(workshop))); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	for i := range namespaceList.Items {
+		namespace := &namespaceList.Items[i]
+		if userNamespaces[namespace.Name] || !metav1.IsControlledBy(namespace, workshop) {
+			continue
+		}
+
+		// Remove the members still pointing to the control plane
+		serviceMeshMemberList := &maistrav1.ServiceMeshMemberList{}
+		if err := r.List(context.TODO(), serviceMeshMemberList,
+			client.MatchingLabels{kubernetes.WorkshopLabel: kubernetes.WorkshopLabelValue(workshop)}); err != nil {
+			return reconcile.Result{}, err
+		}
+		for j := range serviceMeshMemberList.Items {
+			serviceMeshMember := &serviceMeshMemberList.Items[j]
+			if serviceMeshMember.Spec.ControlPlaneRef.Namespace != namespace.Name {
+				continue
+			}
+			if err := r.Delete(context.TODO(), serviceMeshMember); err != nil && !errors.IsNotFound(err) {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Deleted %s Service Mesh Member Custom Resource in %s", serviceMeshMember.Name, serviceMeshMember.Namespace)
+		}
+
+		serviceMeshMemberRoll := &maistrav1.ServiceMeshMemberRoll{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: namespace.Name},
+		}
+		if err := r.Delete(context.TODO(), serviceMeshMemberRoll); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Deleted %s Service Mesh Member Roll Custom Resource in %s", serviceMeshMemberRoll.Name, namespace.Name)
+		}
+
+		serviceMeshControlPlane := &maistrav2.ServiceMeshControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "basic", Namespace: namespace.Name},
+		}
+		if err := r.Delete(context.TODO(), serviceMeshControlPlane); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Deleted %s Service Mesh Control Plane Custom Resource in %s", serviceMeshControlPlane.Name, namespace.Name)
+		}
+
+		if err := r.Delete(context.TODO(), namespace); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Deleted %s Namespace", namespace.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// userControlPlaneLabels returns the labels of the namespaces of the control planes per user
+func userControlPlaneLabels(workshop *workshopv1.Workshop) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/part-of":   "istio",
+		"app.kubernetes.io/component": "user-control-plane",
+		kubernetes.WorkshopLabel:      kubernetes.WorkshopLabelValue(workshop),
+	}
+}
+
+func (r *WorkshopReconciler) addServiceMeshControlPlane(serviceMeshControlPlaneCR *maistrav2.ServiceMeshControlPlane) (reconcile.Result, error) {
+
+	if err := r.Create(context.TODO(), serviceMeshControlPlaneCR); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Service Mesh Control Plane Custom Resource in %s", serviceMeshControlPlaneCR.Name, serviceMeshControlPlaneCR.Namespace)
 	} else if errors.IsAlreadyExists(err) {
-		serviceMeshMemberRollCRFound := &maistrav1.ServiceMeshMemberRoll{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: serviceMeshMemberRollCR.Name, Namespace: istioSystemNamespace.Name}, serviceMeshMemberRollCRFound); err != nil {
+		serviceMeshControlPlaneCRFound := &maistrav2.ServiceMeshControlPlane{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: serviceMeshControlPlaneCR.Name, Namespace: serviceMeshControlPlaneCR.Namespace}, serviceMeshControlPlaneCRFound); err != nil {
 			return reconcile.Result{}, err
 		} else if err == nil {
-			if !reflect.DeepEqual(istioMembers, serviceMeshMemberRollCRFound.Spec.Members) {
-				serviceMeshMemberRollCRFound.Spec.Members = istioMembers
-				if err := r.Update(context.TODO(), serviceMeshMemberRollCRFound); err != nil {
+			// Only the settings exposed in the Workshop are reconciled
+			if serviceMeshControlPlaneCR.Spec.Version != serviceMeshControlPlaneCRFound.Spec.Version ||
+				!reflect.DeepEqual(serviceMeshControlPlaneCR.Spec.Tracing, serviceMeshControlPlaneCRFound.Spec.Tracing) ||
+				!reflect.DeepEqual(serviceMeshControlPlaneCR.Spec.Gateways, serviceMeshControlPlaneCRFound.Spec.Gateways) ||
+				!reflect.DeepEqual(serviceMeshControlPlaneCR.Spec.Addons, serviceMeshControlPlaneCRFound.Spec.Addons) {
+				serviceMeshControlPlaneCRFound.Spec.Version = serviceMeshControlPlaneCR.Spec.Version
+				serviceMeshControlPlaneCRFound.Spec.Tracing = serviceMeshControlPlaneCR.Spec.Tracing
+				serviceMeshControlPlaneCRFound.Spec.Gateways = serviceMeshControlPlaneCR.Spec.Gateways
+				serviceMeshControlPlaneCRFound.Spec.Addons = serviceMeshControlPlaneCR.Spec.Addons
+				if err := r.Update(context.TODO(), serviceMeshControlPlaneCRFound); err != nil {
 					return reconcile.Result{}, err
 				}
-				log.Infof("Updated %s Service Mesh Member Roll Custom Resource", serviceMeshMemberRollCRFound.Name)
+				log.Infof("Updated %s Service Mesh Control Plane Custom Resource in %s", serviceMeshControlPlaneCRFound.Name, serviceMeshControlPlaneCRFound.Namespace)
 			}
 		}
 	}
@@ -179,6 +490,117 @@ func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users
 	return reconcile.Result{}, nil
 }
 
+// serviceMeshControlPlaneNamespace returns the namespace of the control plane used by the user
+func serviceMeshControlPlaneNamespace(workshop *workshopv1.Workshop, username string) string {
+	if workshop.Spec.Infrastructure.ServiceMesh.Tenancy == "PerUser" {
+		return kubernetes.PrefixedName(workshop, username+"-istio-system")
+	}
+	return workshop.Status.Namespaces.ServiceMesh
+}
+
+func (r *WorkshopReconciler) addServiceMeshUserRouting(workshop *workshopv1.Workshop, username string,
+	projectName string, appsHostnameSuffix string) (reconcile.Result, error) {
+
+	userRouting := workshop.Spec.Infrastructure.ServiceMesh.UserRouting
+
+	labels := map[string]string{
+		"app.kubernetes.io/part-of": "istio",
+		"app.kubernetes.io/name":    username,
+	}
+
+	data := maistra.RoutingTemplateData{
+		Username:           username,
+		Project:            projectName,
+		Host:               fmt.Sprintf("%s.%s", projectName, appsHostnameSuffix),
+		AppsHostnameSuffix: appsHostnameSuffix,
+	}
+
+	gatewayTemplate := userRouting.Gateway
+	if gatewayTemplate == "" {
+		gatewayTemplate = maistra.DefaultGatewayTemplate
+	}
+
+	for _, manifest := range []string{gatewayTemplate, userRouting.VirtualService, userRouting.DestinationRule} {
+		if manifest == "" {
+			continue
+		}
+		obj, err := kubernetes.NewUnstructuredFromTemplate(workshop, r.Scheme, manifest, projectName, labels, data)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if err := r.applyUnstructured(obj); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Expose the user host through the Istio Ingress Gateway
+	route := kubernetes.NewRoute(workshop, r.Scheme, projectName, serviceMeshControlPlaneNamespace(workshop, username),
+		labels, "istio-ingressgateway", 8080)
+	route.Spec.Host = data.Host
+	if err := r.Create(context.TODO(), route); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Route", route.Name)
+	} else if errors.IsAlreadyExists(err) {
+		routeFound := &routev1.Route{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, routeFound); err != nil {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			if routeFound.Spec.Host != route.Spec.Host {
+				routeFound.Spec.Host = route.Spec.Host
+				if err := r.Update(context.TODO(), routeFound); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s Route", routeFound.Name)
+			}
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func (r *WorkshopReconciler) addServiceMeshMember(workshop *workshopv1.Workshop, namespace string,
+	labels map[string]string, controlPlaneName string, controlPlaneNamespace string) (reconcile.Result, error) {
+
+	serviceMeshMemberCR := maistra.NewServiceMeshMemberCR(workshop, r.Scheme,
+		"default", namespace, labels, controlPlaneName, controlPlaneNamespace)
+	if err := r.Create(context.TODO(), serviceMeshMemberCR); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Infof("Created %s Service Mesh Member Custom Resource in %s", serviceMeshMemberCR.Name, namespace)
+	} else if errors.IsAlreadyExists(err) {
+		serviceMeshMemberCRFound := &maistrav1.ServiceMeshMember{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: serviceMeshMemberCR.Name, Namespace: namespace}, serviceMeshMemberCRFound); err != nil {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			if !reflect.DeepEqual(serviceMeshMemberCR.Spec.ControlPlaneRef, serviceMeshMemberCRFound.Spec.ControlPlaneRef) ||
+				!reflect.DeepEqual(serviceMeshMemberCR.Labels, serviceMeshMemberCRFound.Labels) {
+				serviceMeshMemberCRFound.Spec.ControlPlaneRef = serviceMeshMemberCR.Spec.ControlPlaneRef
+				serviceMeshMemberCRFound.Labels = serviceMeshMemberCR.Labels
+				if err := r.Update(context.TODO(), serviceMeshMemberCRFound); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s Service Mesh Member Custom Resource in %s", serviceMeshMemberCRFound.Name, namespace)
+			}
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// userMeshProjectNames returns the names of the projects of the user belonging to the mesh
+func userMeshProjectNames(workshop *workshopv1.Workshop, id int) []string {
+	names := []string{}
+	for _, project := range userProjects(workshop, id) {
+		if project.template.ServiceMesh {
+			names = append(names, project.name)
+		}
+	}
+	return names
+}
+
 func (r *WorkshopReconciler) addElasticSearchOperator(workshop *workshopv1.Workshop) (reconcile.Result, error) {
 
 	channel := workshop.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub.Channel
@@ -191,6 +613,9 @@ func (r *WorkshopReconciler) addElasticSearchOperator(workshop *workshopv1.Works
 	} else if err == nil {
 		log.Infof("Created %s Namespace", redhatOperatorsNamespace.Name)
 	}
+	if err := r.acquireSharedNamespace(workshop, redhatOperatorsNamespace); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, subcriptionName, "openshift-operators-redhat",
 		"elasticsearch-operator", channel, clusterserviceversion)
@@ -199,6 +624,9 @@ func (r *WorkshopReconciler) addElasticSearchOperator(workshop *workshopv1.Works
 	} else if err == nil {
 		log.Infof("Created %s Subscription", subscription.Name)
 	}
+	if err := r.acquireShared(workshop, subscription); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	if err := r.ApproveInstallPlan(clusterserviceversion, subcriptionName, "openshift-operators-redhat"); err != nil {
 		log.Infof("Waiting for Subscription to create InstallPlan for %s", subscription.Name)
@@ -214,15 +642,18 @@ func (r *WorkshopReconciler) addJaegerOperator(workshop *workshopv1.Workshop) (r
 	channel := workshop.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub.Channel
 	clusterserviceversion := workshop.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub.ClusterServiceVersion
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, "jaeger-product", "openshift-operators",
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, "jaeger-product", r.operatorsNamespace(),
 		"jaeger-product", channel, clusterserviceversion)
 	if err := r.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Subscription", subscription.Name)
 	}
+	if err := r.acquireShared(workshop, subscription); err != nil {
+		return reconcile.Result{}, err
+	}
 
-	if err := r.ApproveInstallPlan(clusterserviceversion, "jaeger-product", "openshift-operators"); err != nil {
+	if err := r.ApproveInstallPlan(clusterserviceversion, "jaeger-product", r.operatorsNamespace()); err != nil {
 		log.Infof("Waiting for Subscription to create InstallPlan for %s", subscription.Name)
 		return reconcile.Result{Requeue: true}, nil
 	}
@@ -236,15 +667,18 @@ func (r *WorkshopReconciler) addKialiOperator(workshop *workshopv1.Workshop) (re
 	channel := workshop.Spec.Infrastructure.ServiceMesh.KialiOperatorHub.Channel
 	clusterserviceversion := workshop.Spec.Infrastructure.ServiceMesh.KialiOperatorHub.ClusterServiceVersion
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, "kiali-ossm", "openshift-operators",
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, "kiali-ossm", r.operatorsNamespace(),
 		"kiali-ossm", channel, clusterserviceversion)
 	if err := r.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Subscription", subscription.Name)
 	}
+	if err := r.acquireShared(workshop, subscription); err != nil {
+		return reconcile.Result{}, err
+	}
 
-	if err := r.ApproveInstallPlan(clusterserviceversion, "kiali-ossm", "openshift-operators"); err != nil {
+	if err := r.ApproveInstallPlan(clusterserviceversion, "kiali-ossm", r.operatorsNamespace()); err != nil {
 		log.Infof("Waiting for Subscription to create InstallPlan for %s", subscription.Name)
 		return reconcile.Result{Requeue: true}, nil
 	}
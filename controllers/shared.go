@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	certmanager "github.com/mcouliba/workshop-operator/common/certmanager"
+	"github.com/mcouliba/workshop-operator/common/kubernetes"
+	"github.com/mcouliba/workshop-operator/common/util"
+	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/prometheus/common/log"
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// sharedLists are the kinds of the resources shared by the Workshops of the cluster
+func sharedLists() []runtime.Object {
+	return []runtime.Object{
+		&olmv1alpha1.SubscriptionList{},
+		&maistrav2.ServiceMeshControlPlaneList{},
+		&maistrav1.ServiceMeshMemberRollList{},
+		&certmanager.CertManagerList{},
+		&certmanager.ClusterIssuerList{},
+		&certmanager.CertificateList{},
+		&corev1.NamespaceList{},
+		&rbac.RoleList{},
+	}
+}
+
+// acquireShared records the Workshop as a user of the shared resource and removes
+// the Workshop owner references, so that deleting one Workshop does not remove it
+func (r *WorkshopReconciler) acquireShared(workshop *workshopv1.Workshop, obj runtime.Object) error {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+
+	found := obj.DeepCopyObject()
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: objMeta.GetName(), Namespace: objMeta.GetNamespace()}, found); err != nil {
+		return err
+	}
+	foundMeta, err := meta.Accessor(found)
+	if err != nil {
+		return err
+	}
+
+	workshops := sharedWorkshops(foundMeta)
+	ownerReferences := []metav1.OwnerReference{}
+	for _, ownerReference := range foundMeta.GetOwnerReferences() {
+		if ownerReference.APIVersion != workshopv1.GroupVersion.String() || ownerReference.Kind != "Workshop" {
+			ownerReferences = append(ownerReferences, ownerReference)
+		}
+	}
+
+	key := workshopKey(workshop)
+	if util.StringInSlice(key, workshops) && len(ownerReferences) == len(foundMeta.GetOwnerReferences()) {
+		return nil
+	}
+
+	if !util.StringInSlice(key, workshops) {
+		workshops = append(workshops, key)
+	}
+	setSharedWorkshops(foundMeta, workshops)
+	foundMeta.SetOwnerReferences(ownerReferences)
+	if err := r.Update(context.TODO(), found); err != nil {
+		return err
+	}
+	log.Infof("Shared %s with %s Workshop", foundMeta.GetName(), key)
+	return nil
+}
+
+// acquireSharedNamespace shares the namespace created by a Workshop with the other Workshops.
+// The namespaces which existed before any Workshop are never shared, so they are never deleted.
+func (r *WorkshopReconciler) acquireSharedNamespace(workshop *workshopv1.Workshop, namespace *corev1.Namespace) error {
+	found := &corev1.Namespace{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: namespace.Name}, found); err != nil {
+		return err
+	}
+
+	createdByWorkshop := len(sharedWorkshops(found)) > 0
+	for _, ownerReference := range found.GetOwnerReferences() {
+		if ownerReference.APIVersion == workshopv1.GroupVersion.String() && ownerReference.Kind == "Workshop" {
+			createdByWorkshop = true
+		}
+	}
+	if !createdByWorkshop {
+		return nil
+	}
+
+	return r.acquireShared(workshop, namespace)
+}
+
+// releaseShared removes the Workshop from the users of the shared resources
+// and deletes the ones it was the last user of
+func (r *WorkshopReconciler) releaseShared(workshop *workshopv1.Workshop) error {
+	key := workshopKey(workshop)
+
+	for _, list := range sharedLists() {
+		if err := r.List(context.TODO(), list); err != nil {
+			// The operator providing the kind is not installed
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			itemMeta, err := meta.Accessor(item)
+			if err != nil {
+				return err
+			}
+
+			workshops := sharedWorkshops(itemMeta)
+			if !util.StringInSlice(key, workshops) {
+				continue
+			}
+
+			remaining := []string{}
+			for _, workshopUsing := range workshops {
+				if workshopUsing != key {
+					remaining = append(remaining, workshopUsing)
+				}
+			}
+
+			if len(remaining) == 0 {
+				if err := r.Delete(context.TODO(), item); err != nil && !errors.IsNotFound(err) {
+					return err
+				}
+				log.Infof("Deleted %s no longer used by any Workshop", itemMeta.GetName())
+				continue
+			}
+
+			setSharedWorkshops(itemMeta, remaining)
+			if err := r.Update(context.TODO(), item); err != nil {
+				return err
+			}
+			log.Infof("Released %s from %s Workshop", itemMeta.GetName(), key)
+		}
+	}
+
+	return nil
+}
+
+// workshopKey identifies the Workshop in the users of the shared resources
+func workshopKey(workshop *workshopv1.Workshop) string {
+	return workshop.Namespace + "/" + workshop.Name
+}
+
+func sharedWorkshops(objMeta metav1.Object) []string {
+	value := objMeta.GetAnnotations()[kubernetes.WorkshopsAnnotation]
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+func setSharedWorkshops(objMeta metav1.Object, workshops []string) {
+	annotations := objMeta.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[kubernetes.WorkshopsAnnotation] = strings.Join(workshops, ",")
+	objMeta.SetAnnotations(annotations)
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+
+	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// updateComponentStatus sets the status of a component and persists it if it changed
+func (r *WorkshopReconciler) updateComponentStatus(workshop *workshopv1.Workshop, field *string, status string) error {
+	if *field == status {
+		return nil
+	}
+
+	*field = status
+	return r.Status().Update(context.TODO(), workshop)
+}
+
+// updateNamespacesStatus publishes the namespaces of the components and persists them if they changed
+func (r *WorkshopReconciler) updateNamespacesStatus(workshop *workshopv1.Workshop, namespaces workshopv1.NamespacesStatus) error {
+	if workshop.Status.Namespaces == namespaces {
+		return nil
+	}
+
+	workshop.Status.Namespaces = namespaces
+	return r.Status().Update(context.TODO(), workshop)
+}
+
+// updateCondition sets a condition of the Workshop and persists it if it changed
+func (r *WorkshopReconciler) updateCondition(workshop *workshopv1.Workshop, conditionType workshopv1.WorkshopConditionType,
+	status corev1.ConditionStatus, reason string, message string) error {
+
+	condition := workshopv1.WorkshopCondition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+
+	for i, existing := range workshop.Status.Conditions {
+		if existing.Type != conditionType {
+			continue
+		}
+		if existing.Status == status && existing.Reason == reason && existing.Message == message {
+			return nil
+		}
+		if existing.Status == status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		workshop.Status.Conditions[i] = condition
+		return r.Status().Update(context.TODO(), workshop)
+	}
+
+	workshop.Status.Conditions = append(workshop.Status.Conditions, condition)
+	return r.Status().Update(context.TODO(), workshop)
+}
+
+// isConditionTrue returns true if the condition of the Workshop is set and true
+func isConditionTrue(workshop *workshopv1.Workshop, conditionType workshopv1.WorkshopConditionType) bool {
+	for _, condition := range workshop.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
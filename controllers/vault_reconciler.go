@@ -1,7 +1,15 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
 
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
 	"github.com/mcouliba/workshop-operator/common/kubernetes"
@@ -9,8 +17,12 @@ import (
 	"github.com/mcouliba/workshop-operator/common/vault"
 	securityv1 "github.com/openshift/api/security/v1"
 	"github.com/prometheus/common/log"
+	admissionregistration "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -23,6 +35,14 @@ func (r *WorkshopReconciler) reconcileVault(workshop *workshopv1.Workshop, users
 			return result, err
 		}
 
+		if result, err := r.initVault(workshop, users); util.IsRequeued(result, err) {
+			return result, err
+		}
+
+		if result, err := r.updateVaultPods(workshop); util.IsRequeued(result, err) {
+			return result, err
+		}
+
 		if result, err := r.addVaultAgentInjector(workshop, users); util.IsRequeued(result, err) {
 			return result, err
 		}
@@ -40,26 +60,35 @@ func (r *WorkshopReconciler) addVaultServer(workshop *workshopv1.Workshop, users
 		"component":                 "server",
 	}
 
-	namespace := kubernetes.NewNamespace(workshop, r.Scheme, "vault")
+	namespace := kubernetes.NewNamespace(workshop, r.Scheme, workshop.Status.Namespaces.Vault)
 	if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Project", namespace.Name)
 	}
 
-	extraconfigFromValues := map[string]string{
-		"extraconfig-from-values.hcl": `disable_mlock = true
-ui = true
+	replicas := vaultReplicas(workshop)
+	configuration := vault.NewConfiguration("vault", replicas)
 
-listener "tcp" {
-	tls_disable = 1
-	address = "[::]:8200"
-	cluster_address = "[::]:8201"
-}
-storage "file" {
-	path = "/vault/data"
-}
-`,
+	// The storage of an existing Vault is not migrated between file and Raft
+	currentConfigMap := &corev1.ConfigMap{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "vault-config", Namespace: namespace.Name}, currentConfigMap); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	} else if err == nil && vault.IsRaftStorage(currentConfigMap.Data["extraconfig-from-values.hcl"]) != vault.IsRaftStorage(configuration) {
+		log.Errorf("The high availability of the existing Vault cannot be switched")
+		if err := r.updateCondition(workshop, workshopv1.WorkshopVaultStorageValid, corev1.ConditionFalse, "StorageSwitched",
+			"The high availability of an existing Vault cannot be switched because its storage is not migrated between file and Raft"); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.updateCondition(workshop, workshopv1.WorkshopVaultStorageValid, corev1.ConditionTrue, "Valid", ""); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	extraconfigFromValues := map[string]string{
+		"extraconfig-from-values.hcl": configuration,
 	}
 
 	configMap := kubernetes.NewConfigMap(workshop, r.Scheme, "vault-config", namespace.Name, labels, extraconfigFromValues)
@@ -67,6 +96,18 @@ storage "file" {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s ConfigMap", configMap.Name)
+	} else if errors.IsAlreadyExists(err) {
+		configMapFound := &corev1.ConfigMap{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: configMap.Name, Namespace: namespace.Name}, configMapFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		if !reflect.DeepEqual(configMap.Data, configMapFound.Data) {
+			configMapFound.Data = configMap.Data
+			if err := r.Update(context.TODO(), configMapFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Updated %s ConfigMap", configMapFound.Name)
+		}
 	}
 
 	// Create Service Account
@@ -77,23 +118,20 @@ storage "file" {
 		log.Infof("Created %s Service Account", serviceAccount.Name)
 	}
 
-	serviceAccountUser := "system:serviceaccount:" + namespace.Name + ":" + serviceAccount.Name
-
-	privilegedSCCFound := &securityv1.SecurityContextConstraints{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: "privileged"}, privilegedSCCFound); err != nil {
-		return reconcile.Result{}, err
+	// Vault runs as a fixed non-root user and locks its memory
+	sccLabels := map[string]string{
+		"app.kubernetes.io/part-of": "vault",
+		kubernetes.WorkshopLabel:    kubernetes.WorkshopLabelValue(workshop),
 	}
-	if !util.StringInSlice(serviceAccountUser, privilegedSCCFound.Users) {
-		privilegedSCCFound.Users = append(privilegedSCCFound.Users, serviceAccountUser)
-		if err := r.Update(context.TODO(), privilegedSCCFound); err != nil {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Updated %s SCC", privilegedSCCFound.Name)
-		}
+	scc := kubernetes.NewSecurityContextConstraints(workshop, r.Scheme,
+		kubernetes.PrefixedName(workshop, "workshop-vault"), sccLabels, securityv1.RunAsUserStrategyMustRunAsNonRoot,
+		[]corev1.Capability{"IPC_LOCK"}, false)
+	if result, err := r.addSecurityContextConstraints(workshop, scc, namespace.Name, serviceAccount.Name); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	// Create ClusterRole Binding
-	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, "vault-server-binding", namespace.Name,
+	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, kubernetes.PrefixedName(workshop, "vault-server-binding"), namespace.Name,
 		labels, serviceAccount.Name, "system:auth-delegator", "ClusterRole")
 	if err := r.Create(context.TODO(), clusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
@@ -102,11 +140,25 @@ storage "file" {
 	}
 
 	// Create Service
-	internalService := kubernetes.NewService(workshop, r.Scheme, "vault-internal", namespace.Name, labels, []string{"http", "internal"}, []int32{8200, 8201})
+	// The internal service is headless so that sealed pods can be reached to be unsealed
+	internalService := kubernetes.NewHeadlessService(workshop, r.Scheme, "vault-internal", namespace.Name, labels, []string{"http", "internal"}, []int32{8200, 8201})
 	if err := r.Create(context.TODO(), internalService); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Service", internalService.Name)
+	} else if errors.IsAlreadyExists(err) {
+		internalServiceFound := &corev1.Service{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: internalService.Name, Namespace: namespace.Name}, internalServiceFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		// The cluster IP is immutable so the service has to be recreated
+		if internalServiceFound.Spec.ClusterIP != corev1.ClusterIPNone {
+			if err := r.Delete(context.TODO(), internalServiceFound); err != nil && !errors.IsNotFound(err) {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Deleted %s Service", internalServiceFound.Name)
+			return reconcile.Result{Requeue: true}, nil
+		}
 	}
 
 	service := kubernetes.NewService(workshop, r.Scheme, "vault", namespace.Name, labels, []string{"http", "internal"}, []int32{8200, 8201})
@@ -116,18 +168,443 @@ storage "file" {
 		log.Infof("Created %s Service", service.Name)
 	}
 
-	// Create Stateful
-	stateful := vault.NewStatefulSet(workshop, r.Scheme, "vault", namespace.Name, labels)
+	// Create/Update Stateful
+	// Pods are replaced by the operator so that the cluster stays available
+	stateful := vault.NewStatefulSet(workshop, r.Scheme, "vault", namespace.Name, labels, replicas)
+	stateful.Spec.Template.Annotations = map[string]string{
+		"checksum/config": fmt.Sprintf("%x", sha256.Sum256([]byte(extraconfigFromValues["extraconfig-from-values.hcl"]))),
+	}
 	if err := r.Create(context.TODO(), stateful); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Stateful", stateful.Name)
+	} else if errors.IsAlreadyExists(err) {
+		statefulFound := &appsv1.StatefulSet{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: stateful.Name, Namespace: namespace.Name}, statefulFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		if !reflect.DeepEqual(stateful.Spec.Replicas, statefulFound.Spec.Replicas) ||
+			!reflect.DeepEqual(stateful.Spec.Template.Annotations, statefulFound.Spec.Template.Annotations) ||
+			!reflect.DeepEqual(stateful.Spec.Template.Spec.Containers[0].Image, statefulFound.Spec.Template.Spec.Containers[0].Image) {
+			statefulFound.Spec.Replicas = stateful.Spec.Replicas
+			statefulFound.Spec.Template = stateful.Spec.Template
+			if err := r.Update(context.TODO(), statefulFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Updated %s Stateful", statefulFound.Name)
+		}
+	}
+
+	// Create/Delete Pod Disruption Budget
+	podDisruptionBudget := kubernetes.NewPodDisruptionBudget(workshop, r.Scheme, "vault", namespace.Name, labels, 1)
+	if replicas > 1 {
+		if err := r.Create(context.TODO(), podDisruptionBudget); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Created %s Pod Disruption Budget", podDisruptionBudget.Name)
+		}
+	} else {
+		if err := r.Delete(context.TODO(), podDisruptionBudget); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Infof("Deleted %s Pod Disruption Budget", podDisruptionBudget.Name)
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// initVault initializes and unseals Vault, then configures the Kubernetes auth method for every user
+func (r *WorkshopReconciler) initVault(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
+	labels := map[string]string{
+		"app":                       "vault",
+		"app.kubernetes.io/name":    "vault",
+		"app.kubernetes.io/part-of": "vault",
+		"component":                 "server",
+	}
+
+	namespace := workshop.Status.Namespaces.Vault
+	unsealKeysSecretName := "vault-unseal-keys"
+
+	statefulsetFound := &appsv1.StatefulSet{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "vault", Namespace: namespace}, statefulsetFound); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	replicas := int32(1)
+	if statefulsetFound.Spec.Replicas != nil {
+		replicas = *statefulsetFound.Spec.Replicas
+	}
+
+	// Every pod is reached directly through the headless service
+	leaderURL := fmt.Sprintf("http://vault-0.vault-internal.%s.svc:8200", namespace)
+
+	initStatus := &vault.InitStatus{}
+	if statusCode, err := callVaultAPI("GET", leaderURL+"/v1/sys/init", "", nil, initStatus); err != nil {
+		log.Warnf("Waiting for Vault to be reachable: %s", err)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
+	} else if statusCode != http.StatusOK {
+		log.Errorf("Error when getting the Vault initialization status (%d)", statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	unsealKeysSecretFound := &corev1.Secret{}
+	unsealKeysSecretErr := r.Get(context.TODO(), types.NamespacedName{Name: unsealKeysSecretName, Namespace: namespace}, unsealKeysSecretFound)
+	if unsealKeysSecretErr != nil && !errors.IsNotFound(unsealKeysSecretErr) {
+		return reconcile.Result{}, unsealKeysSecretErr
+	}
+
+	_, keysStored := unsealKeysSecretFound.Data["root-token"]
+
+	if !initStatus.Initialized {
+		// Keys of a previous initialization are never overwritten
+		if keysStored {
+			log.Errorf("Vault is not initialized but the %s Secret already holds keys", unsealKeysSecretName)
+			if err := r.updateCondition(workshop, workshopv1.WorkshopVaultInitialized, corev1.ConditionFalse, "KeysFound",
+				fmt.Sprintf("Vault is not initialized but the %s Secret already holds keys, delete it to initialize Vault again", unsealKeysSecretName)); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Minute}, nil
+		}
+
+		// The Secret is created before the initialization so that the keys can always be stored
+		if unsealKeysSecretErr != nil {
+			unsealKeysSecret := kubernetes.NewStringDataSecret(workshop, r.Scheme, unsealKeysSecretName, namespace, labels, map[string]string{})
+			if err := r.Create(context.TODO(), unsealKeysSecret); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Created %s Secret", unsealKeysSecret.Name)
+			return reconcile.Result{Requeue: true}, nil
+		}
+
+		initResponse := &vault.InitResponse{}
+		if statusCode, err := callVaultAPI("PUT", leaderURL+"/v1/sys/init", "", vault.NewInitRequest(), initResponse); err != nil {
+			return reconcile.Result{}, err
+		} else if statusCode != http.StatusOK {
+			log.Errorf("Error when initializing Vault (%d)", statusCode)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+		log.Infof("Initialized Vault")
+
+		unsealKeysSecretFound.StringData = map[string]string{
+			"root-token": initResponse.RootToken,
+		}
+		for i, key := range initResponse.KeysBase64 {
+			unsealKeysSecretFound.StringData[fmt.Sprintf("unseal-key-%d", i+1)] = key
+		}
+
+		if err := r.Update(context.TODO(), unsealKeysSecretFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Updated %s Secret", unsealKeysSecretFound.Name)
+
+		return reconcile.Result{Requeue: true}, nil
+	} else if !keysStored {
+		log.Errorf("Vault is initialized but the %s Secret does not hold its keys", unsealKeysSecretName)
+		if err := r.updateCondition(workshop, workshopv1.WorkshopVaultInitialized, corev1.ConditionFalse, "KeysMissing",
+			fmt.Sprintf("Vault is initialized but the %s Secret does not hold its keys", unsealKeysSecretName)); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Minute}, nil
+	}
+
+	if err := r.updateCondition(workshop, workshopv1.WorkshopVaultInitialized, corev1.ConditionTrue, "Initialized", ""); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	rootToken := string(unsealKeysSecretFound.Data["root-token"])
+	unsealKeys := []string{}
+	for i := 1; ; i++ {
+		key, found := unsealKeysSecretFound.Data[fmt.Sprintf("unseal-key-%d", i)]
+		if !found {
+			break
+		}
+		unsealKeys = append(unsealKeys, string(key))
+	}
+
+	// Unseal every pod, including those restarted since the last reconciliation
+	for id := int32(0); id < replicas; id++ {
+		podURL := fmt.Sprintf("http://vault-%d.vault-internal.%s.svc:8200", id, namespace)
+		if result, err := unsealVault(podURL, unsealKeys); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	// Kubernetes auth method
+	authMethods := map[string]interface{}{}
+	if statusCode, err := callVaultAPI("GET", leaderURL+"/v1/sys/auth", rootToken, nil, &authMethods); err != nil {
+		return reconcile.Result{}, err
+	} else if statusCode != http.StatusOK {
+		log.Errorf("Error when listing Vault auth methods (%d)", statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	if _, found := authMethods["kubernetes/"]; !found {
+		if statusCode, err := callVaultAPI("POST", leaderURL+"/v1/sys/auth/kubernetes", rootToken,
+			map[string]string{"type": "kubernetes"}, nil); err != nil {
+			return reconcile.Result{}, err
+		} else if statusCode != http.StatusNoContent {
+			log.Errorf("Error when enabling the Vault Kubernetes auth method (%d)", statusCode)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+		log.Infof("Enabled Vault Kubernetes auth method")
+	}
+
+	// Vault reviews tokens with its own service account token and CA.
+	// The configuration is written on every reconciliation to recover from a failed or changed one.
+	if statusCode, err := callVaultAPI("POST", leaderURL+"/v1/auth/kubernetes/config", rootToken,
+		map[string]string{"kubernetes_host": "https://kubernetes.default.svc:443"}, nil); err != nil {
+		return reconcile.Result{}, err
+	} else if statusCode != http.StatusNoContent {
+		log.Errorf("Error when configuring the Vault Kubernetes auth method (%d)", statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	// KV secrets engine
+	mounts := map[string]interface{}{}
+	if statusCode, err := callVaultAPI("GET", leaderURL+"/v1/sys/mounts", rootToken, nil, &mounts); err != nil {
+		return reconcile.Result{}, err
+	} else if statusCode != http.StatusOK {
+		log.Errorf("Error when listing Vault secrets engines (%d)", statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	if _, found := mounts["secret/"]; !found {
+		kvEngine := map[string]interface{}{
+			"type": "kv",
+			"options": map[string]string{
+				"version": "2",
+			},
+		}
+		if statusCode, err := callVaultAPI("POST", leaderURL+"/v1/sys/mounts/secret", rootToken, kvEngine, nil); err != nil {
+			return reconcile.Result{}, err
+		} else if statusCode != http.StatusNoContent {
+			log.Errorf("Error when enabling the Vault KV secrets engine (%d)", statusCode)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+		log.Infof("Enabled Vault KV secrets engine")
+	}
+
+	// Policy and role per user
+	if workshop.Spec.Infrastructure.Project.Enabled && len(projectTemplates(workshop)) > 0 {
+		for id := 1; id <= users; id++ {
+			username := fmt.Sprintf("user%d", id)
+
+			if statusCode, err := callVaultAPI("PUT", leaderURL+"/v1/sys/policies/acl/"+username, rootToken,
+				map[string]string{"policy": vault.NewUserPolicy(username)}, nil); err != nil {
+				return reconcile.Result{}, err
+			} else if statusCode != http.StatusNoContent {
+				log.Errorf("Error when writing %s policy in Vault (%d)", username, statusCode)
+				return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+			}
+
+			if statusCode, err := callVaultAPI("POST", leaderURL+"/v1/auth/kubernetes/role/"+username, rootToken,
+				vault.NewKubernetesRole(userProjectNames(workshop, id), username), nil); err != nil {
+				return reconcile.Result{}, err
+			} else if statusCode != http.StatusNoContent {
+				log.Errorf("Error when writing %s role in Vault (%d)", username, statusCode)
+				return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+			}
+		}
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
+// updateVaultPods replaces the outdated pods one at a time, followers first,
+// and steps the leader down before replacing it
+func (r *WorkshopReconciler) updateVaultPods(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+
+	namespace := workshop.Status.Namespaces.Vault
+
+	statefulsetFound := &appsv1.StatefulSet{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "vault", Namespace: namespace}, statefulsetFound); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	updateRevision := statefulsetFound.Status.UpdateRevision
+	if updateRevision == "" {
+		return reconcile.Result{}, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(context.TODO(), pods, client.InNamespace(namespace),
+		client.MatchingLabels(statefulsetFound.Spec.Selector.MatchLabels)); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	outdatedPods := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		if pod.Labels[appsv1.StatefulSetRevisionLabel] != updateRevision {
+			outdatedPods = append(outdatedPods, pod)
+		}
+	}
+
+	if len(outdatedPods) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	// Never take down a pod while another one is not ready
+	if int32(len(pods.Items)) != *statefulsetFound.Spec.Replicas {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+	for _, pod := range pods.Items {
+		if !isPodReady(&pod) {
+			log.Infof("Waiting for %s pod to be ready before updating Vault", pod.Name)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+	}
+
+	var leaderPod *corev1.Pod
+	for i, pod := range outdatedPods {
+		leader := &vault.LeaderStatus{}
+		podURL := fmt.Sprintf("http://%s.vault-internal.%s.svc:8200", pod.Name, namespace)
+		if statusCode, err := callVaultAPI("GET", podURL+"/v1/sys/leader", "", nil, leader); err != nil {
+			return reconcile.Result{}, err
+		} else if statusCode != http.StatusOK {
+			log.Errorf("Error when getting the Vault leader from %s (%d)", pod.Name, statusCode)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+
+		if leader.IsSelf {
+			leaderPod = &outdatedPods[i]
+			continue
+		}
+
+		// Follower
+		if err := r.Delete(context.TODO(), &outdatedPods[i]); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Deleted %s pod to update Vault", pod.Name)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	if leaderPod != nil {
+		if *statefulsetFound.Spec.Replicas > 1 {
+			unsealKeysSecretFound := &corev1.Secret{}
+			if err := r.Get(context.TODO(), types.NamespacedName{Name: "vault-unseal-keys", Namespace: namespace}, unsealKeysSecretFound); err != nil {
+				return reconcile.Result{}, err
+			}
+
+			podURL := fmt.Sprintf("http://%s.vault-internal.%s.svc:8200", leaderPod.Name, namespace)
+			if statusCode, err := callVaultAPI("PUT", podURL+"/v1/sys/step-down", string(unsealKeysSecretFound.Data["root-token"]), nil, nil); err != nil {
+				return reconcile.Result{}, err
+			} else if statusCode != http.StatusNoContent {
+				log.Errorf("Error when stepping down the Vault leader %s (%d)", leaderPod.Name, statusCode)
+				return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+			}
+			log.Infof("Stepped down %s Vault leader", leaderPod.Name)
+		}
+
+		if err := r.Delete(context.TODO(), leaderPod); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Infof("Deleted %s pod to update Vault", leaderPod.Name)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// vaultReplicas returns the number of Vault servers to run
+func vaultReplicas(workshop *workshopv1.Workshop) int32 {
+	if !workshop.Spec.Infrastructure.Vault.HighAvailability.Enabled {
+		return 1
+	}
+	if workshop.Spec.Infrastructure.Vault.HighAvailability.Replicas < 3 {
+		return 3
+	}
+	return workshop.Spec.Infrastructure.Vault.HighAvailability.Replicas
+}
+
+func unsealVault(podURL string, unsealKeys []string) (reconcile.Result, error) {
+
+	sealStatus := &vault.SealStatus{}
+	if statusCode, err := callVaultAPI("GET", podURL+"/v1/sys/seal-status", "", nil, sealStatus); err != nil {
+		log.Warnf("Waiting for %s to be reachable: %s", podURL, err)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
+	} else if statusCode != http.StatusOK {
+		log.Errorf("Error when getting the Vault seal status (%d)", statusCode)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	for _, key := range unsealKeys {
+		if !sealStatus.Sealed {
+			break
+		}
+		if statusCode, err := callVaultAPI("PUT", podURL+"/v1/sys/unseal", "", &vault.UnsealRequest{Key: key}, sealStatus); err != nil {
+			return reconcile.Result{}, err
+		} else if statusCode != http.StatusOK {
+			log.Errorf("Error when unsealing Vault (%d)", statusCode)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		}
+	}
+
+	if sealStatus.Sealed {
+		log.Errorf("Vault is still sealed after using every unseal key")
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// callVaultAPI sends the request payload as JSON and decodes the response into result when provided
+func callVaultAPI(method string, requestURL string, token string, payload interface{}, result interface{}) (int, error) {
+
+	var (
+		err          error
+		body         []byte
+		httpResponse *http.Response
+		httpRequest  *http.Request
+		client       = &http.Client{
+			Timeout: time.Second * 10,
+		}
+	)
+
+	if payload != nil {
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	httpRequest, err = http.NewRequest(method, requestURL, bytes.NewBuffer(body))
+	if err != nil {
+		return 0, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpRequest.Header.Set("X-Vault-Token", token)
+	}
+
+	httpResponse, err = client.Do(httpRequest)
+	if err != nil {
+		return 0, err
+	}
+	defer httpResponse.Body.Close()
+
+	if result != nil && httpResponse.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(httpResponse.Body).Decode(result); err != nil {
+			return 0, err
+		}
+	}
+
+	return httpResponse.StatusCode, nil
+}
+
 func (r *WorkshopReconciler) addVaultAgentInjector(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
 	labels := map[string]string{
 		"app":                       "vault",
@@ -136,7 +613,7 @@ func (r *WorkshopReconciler) addVaultAgentInjector(workshop *workshopv1.Workshop
 		"component":                 "webhook",
 	}
 
-	namespace := kubernetes.NewNamespace(workshop, r.Scheme, "vault")
+	namespace := kubernetes.NewNamespace(workshop, r.Scheme, workshop.Status.Namespaces.Vault)
 	if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
@@ -151,31 +628,28 @@ func (r *WorkshopReconciler) addVaultAgentInjector(workshop *workshopv1.Workshop
 		log.Infof("Created %s Service Account", serviceAccount.Name)
 	}
 
-	serviceAccountUser := "system:serviceaccount:" + namespace.Name + ":" + serviceAccount.Name
-
-	privilegedSCCFound := &securityv1.SecurityContextConstraints{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: "privileged"}, privilegedSCCFound); err != nil {
-		return reconcile.Result{}, err
+	// The injector runs as a fixed non-root user
+	sccLabels := map[string]string{
+		"app.kubernetes.io/part-of": "vault",
+		kubernetes.WorkshopLabel:    kubernetes.WorkshopLabelValue(workshop),
 	}
-
-	if !util.StringInSlice(serviceAccountUser, privilegedSCCFound.Users) {
-		privilegedSCCFound.Users = append(privilegedSCCFound.Users, serviceAccountUser)
-		if err := r.Update(context.TODO(), privilegedSCCFound); err != nil {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Updated %s SCC", privilegedSCCFound.Name)
-		}
+	scc := kubernetes.NewSecurityContextConstraints(workshop, r.Scheme,
+		kubernetes.PrefixedName(workshop, "workshop-vault-agent-injector"), sccLabels, securityv1.RunAsUserStrategyMustRunAsNonRoot,
+		nil, false)
+	if result, err := r.addSecurityContextConstraints(workshop, scc, namespace.Name, serviceAccount.Name); util.IsRequeued(result, err) {
+		return result, err
 	}
+
 	// Create Cluster Role
 	clusterRole := kubernetes.NewClusterRole(workshop, r.Scheme,
-		"vault-agent-injector", namespace.Name, labels, kubernetes.VaultAgentInjectorRules())
+		kubernetes.PrefixedName(workshop, "vault-agent-injector"), namespace.Name, labels, kubernetes.VaultAgentInjectorRules())
 	if err := r.Create(context.TODO(), clusterRole); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Cluster Role", clusterRole.Name)
 	}
 
-	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, "vault-agent-injector", namespace.Name,
+	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, kubernetes.PrefixedName(workshop, "vault-agent-injector"), namespace.Name,
 		labels, "vault-agent-injector", clusterRole.Name, "ClusterRole")
 	if err := r.Create(context.TODO(), clusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
@@ -192,24 +666,122 @@ func (r *WorkshopReconciler) addVaultAgentInjector(workshop *workshopv1.Workshop
 		log.Infof("Created %s Service", service.Name)
 	}
 
+	// Create/Rotate the webhook certificate
+	tlsSecret, result, err := r.manageAgentInjectorCertificate(workshop, namespace.Name, labels)
+	if util.IsRequeued(result, err) {
+		return result, err
+	}
+
 	// Create Deployment
-	ocpDeployment := vault.NewAgentInjectorDeployment(workshop, r.Scheme, "vault-agent-injector", namespace.Name, labels)
+	ocpDeployment := vault.NewAgentInjectorDeployment(workshop, r.Scheme, "vault-agent-injector", namespace.Name, labels, tlsSecret.Name)
 	if err := r.Create(context.TODO(), ocpDeployment); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Deployment", ocpDeployment.Name)
+	} else if errors.IsAlreadyExists(err) {
+		deploymentFound := &appsv1.Deployment{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: ocpDeployment.Name, Namespace: namespace.Name}, deploymentFound); err != nil {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			if !reflect.DeepEqual(ocpDeployment.Spec.Template.Spec.Containers[0].Env, deploymentFound.Spec.Template.Spec.Containers[0].Env) ||
+				!reflect.DeepEqual(ocpDeployment.Spec.Template.Spec.Volumes, deploymentFound.Spec.Template.Spec.Volumes) {
+				// Update Agent Injector
+				if err := r.Update(context.TODO(), ocpDeployment); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Infof("Updated %s Deployment", ocpDeployment.Name)
+			}
+		}
 	}
 
-	// Create
-	webhooks := vault.NewAgentInjectorWebHook(namespace.Name)
+	// Create/Update Mutating Webhook Configuration
+	webhooks := vault.NewAgentInjectorWebHook(namespace.Name, tlsSecret.Data["ca.crt"])
 	mutatingWebhookConfiguration := kubernetes.NewMutatingWebhookConfiguration(workshop, r.Scheme,
-		"vault-agent-injector-cfg", labels, webhooks)
+		kubernetes.PrefixedName(workshop, "vault-agent-injector-cfg"), labels, webhooks)
 	if err := r.Create(context.TODO(), mutatingWebhookConfiguration); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		log.Infof("Created %s Mutating Webhook Configuration", mutatingWebhookConfiguration.Name)
+	} else if errors.IsAlreadyExists(err) {
+		mutatingWebhookConfigurationFound := &admissionregistration.MutatingWebhookConfiguration{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: mutatingWebhookConfiguration.Name}, mutatingWebhookConfigurationFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		if len(mutatingWebhookConfigurationFound.Webhooks) != len(webhooks) ||
+			!bytes.Equal(mutatingWebhookConfigurationFound.Webhooks[0].ClientConfig.CABundle, webhooks[0].ClientConfig.CABundle) ||
+			!reflect.DeepEqual(mutatingWebhookConfigurationFound.Webhooks[0].FailurePolicy, webhooks[0].FailurePolicy) {
+			mutatingWebhookConfigurationFound.Webhooks = webhooks
+			if err := r.Update(context.TODO(), mutatingWebhookConfigurationFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Infof("Updated %s Mutating Webhook Configuration", mutatingWebhookConfigurationFound.Name)
+		}
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// manageAgentInjectorCertificate generates the CA and serving certificate of the webhook
+// and renews both before they expire
+func (r *WorkshopReconciler) manageAgentInjectorCertificate(workshop *workshopv1.Workshop,
+	namespace string, labels map[string]string) (*corev1.Secret, reconcile.Result, error) {
+
+	secretName := "vault-agent-injector-tls"
+	serviceName := "vault-agent-injector"
+	validity := time.Hour * 24 * 365
+	renewBefore := time.Hour * 24 * 30
+
+	secretFound := &corev1.Secret{}
+	secretErr := r.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: namespace}, secretFound)
+	if secretErr != nil && !errors.IsNotFound(secretErr) {
+		return nil, reconcile.Result{}, secretErr
+	}
+
+	if secretErr == nil && !util.IsCertificateExpiring(secretFound.Data["tls.crt"], renewBefore) &&
+		!util.IsCertificateExpiring(secretFound.Data["ca.crt"], renewBefore) {
+		return secretFound, reconcile.Result{}, nil
+	}
+
+	ca, err := util.NewCACertificate(serviceName+"-ca", validity)
+	if err != nil {
+		return nil, reconcile.Result{}, err
+	}
+
+	hosts := []string{
+		serviceName,
+		serviceName + "." + namespace,
+		serviceName + "." + namespace + ".svc",
+		serviceName + "." + namespace + ".svc.cluster.local",
+	}
+	serving, err := util.NewServingCertificate(ca, hosts, validity)
+	if err != nil {
+		return nil, reconcile.Result{}, err
+	}
+
+	// Keep trusting the previous CA for one rotation, until the injector serves the renewed certificate
+	caBundle := ca.Cert
+	if secretErr == nil {
+		if previousCA, err := util.ParseCertificate(secretFound.Data["ca.crt"]); err == nil && time.Now().Before(previousCA.NotAfter) {
+			caBundle = append(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: previousCA.Raw})...)
+		}
+	}
+
+	secret := kubernetes.NewTLSSecret(workshop, r.Scheme, secretName, namespace, labels, caBundle, serving.Cert, serving.Key)
+	if errors.IsNotFound(secretErr) {
+		if err := r.Create(context.TODO(), secret); err != nil {
+			return nil, reconcile.Result{}, err
+		}
+		log.Infof("Created %s Secret", secret.Name)
+	} else {
+		secretFound.Data = secret.Data
+		if err := r.Update(context.TODO(), secretFound); err != nil {
+			return nil, reconcile.Result{}, err
+		}
+		secret = secretFound
+		log.Infof("Renewed %s Secret", secret.Name)
+	}
+
+	//Success
+	return secret, reconcile.Result{}, nil
+}
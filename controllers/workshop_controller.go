@@ -18,21 +18,30 @@ package controllers
 
 import (
 	"context"
-	"regexp"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
-	routev1 "github.com/openshift/api/route/v1"
 	"github.com/prometheus/common/log"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	workshopv1 "github.com/mcouliba/workshop-operator/api/v1"
+	"github.com/mcouliba/workshop-operator/common/kubernetes"
 	"github.com/mcouliba/workshop-operator/common/util"
 )
 
@@ -41,6 +50,14 @@ type WorkshopReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// Platform is detected at startup
+	Platform kubernetes.Platform
+	// RESTMapper resolves the scope of the kinds of the seed manifests
+	RESTMapper meta.RESTMapper
+
+	// seedCache holds the seed manifests fetched from git per Workshop
+	seedCache      map[string]seedManifests
+	seedCacheMutex sync.Mutex
 }
 
 // Finalizer
@@ -49,21 +66,26 @@ const workshopFinalizer = "finalizer.workshop.mcouliba.com"
 // +kubebuilder:rbac:groups=workshop.mcouliba.com,resources=workshops;workshops/finalizers,verbs=*
 // +kubebuilder:rbac:groups=workshop.mcouliba.com,resources=workshops/status,verbs=get;update;patch
 
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/finalizers,verbs=update
-// +kubebuilder:rbac:groups=core,resources=pods;services;endpoints;persistentvolumeclaims;events;configmaps;secrets;namespaces;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods;services;endpoints;persistentvolumeclaims;events;configmaps;secrets;namespaces;serviceaccounts;resourcequotas;limitranges,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=list;watch;update
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses;networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=config.openshift.io,resources=ingresses;consoles,verbs=get;list;watch
+// +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=project.openshift.io,resources=projectrequests,verbs=create
 
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings;clusterroles;clusterrolebindings,verbs=*
-// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=org.eclipse.che,resources=checlusters,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=maistra.io,resources=servicemeshcontrolplanes;servicemeshmemberrolls,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=maistra.io,resources=servicemeshcontrolplanes;servicemeshmemberrolls;servicemeshmembers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=maistra.io,resources=servicemeshcontrolplanes,verbs=use
+// +kubebuilder:rbac:groups=operator.cert-manager.io,resources=certmanagers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=clusterissuers;certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.istio.io,resources=gateways;virtualservices;destinationrules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=gpte.opentlc.com,resources=nexus;giteas,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=operators.coreos.com,resources=operatorgroups;subscriptions;clusterserviceversions;installplans,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=argoproj.io,resources=argocds;appprojects,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=argoproj.io,resources=argocds;appprojects;applications,verbs=get;list;watch;create;update;patch;delete
 
 func (r *WorkshopReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -120,27 +142,79 @@ func (r *WorkshopReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	//////////////////////////
 	// Variables
 	//////////////////////////
-	var (
-		openshiftConsoleURL string
-		appsHostnameSuffix  string
-	)
-	// extract app route suffix from openshift-console
-	route := &routev1.Route{}
-	if err := r.Get(ctx, types.NamespacedName{Name: "console", Namespace: "openshift-console"}, route); err != nil {
-		log.Errorf("Failed to get OpenShift Console: %s", err)
+	appsHostnameSuffix, openshiftConsoleURL, err := r.discoverCluster(workshop)
+	if err != nil {
+		log.Errorf("Failed to discover the cluster: %s", err)
+		if err := r.updateCondition(workshop, workshopv1.WorkshopClusterDiscovered,
+			corev1.ConditionFalse, "DiscoveryFailed", err.Error()); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{RequeueAfter: time.Minute}, nil
+	}
+	discoveredMessage := fmt.Sprintf("%s cluster with apps domain %s", r.Platform, appsHostnameSuffix)
+	if openshiftConsoleURL != "" {
+		discoveredMessage += fmt.Sprintf(" and console %s", openshiftConsoleURL)
+	}
+	if err := r.updateCondition(workshop, workshopv1.WorkshopClusterDiscovered, corev1.ConditionTrue, "Discovered",
+		discoveredMessage); err != nil {
 		return reconcile.Result{}, err
 	}
-	openshiftConsoleURL = "https://" + route.Spec.Host
 
-	re := regexp.MustCompile("^console-openshift-console\\.(.*?)$")
-	match := re.FindStringSubmatch(route.Spec.Host)
-	appsHostnameSuffix = match[1]
+	if err := r.updateNamespacesStatus(workshop, r.resolveNamespaces(workshop)); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	users := workshop.Spec.User.Number
 	if users < 0 {
 		users = 0
 	}
 
+	if workshop.Spec.Infrastructure.Project.Enabled {
+		if err := validateProjectTemplates(workshop, users); err != nil {
+			log.Errorf("Invalid project templates: %s", err)
+			// A fixed Workshop spec triggers a new reconciliation
+			return reconcile.Result{}, r.updateCondition(workshop, workshopv1.WorkshopProjectTemplatesValid,
+				corev1.ConditionFalse, "InvalidName", err.Error())
+		}
+		if err := r.updateCondition(workshop, workshopv1.WorkshopProjectTemplatesValid, corev1.ConditionTrue, "Valid",
+			"Project templates render valid namespace names"); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	//////////////////////////
+	// Schedule
+	//////////////////////////
+	provision, scheduleResult, err := r.reconcileSchedule(workshop, users, appsHostnameSuffix)
+	if err != nil || !provision {
+		return scheduleResult, err
+	}
+
+	//////////////////////////
+	// Usage
+	//////////////////////////
+	// Refreshed periodically, even while the next steps are requeued
+	usageResult, err := r.reconcileUsage(workshop, users)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	requeueResult := util.EarliestRequeue(scheduleResult, usageResult)
+
+	//////////////////////////
+	// Hibernate
+	//////////////////////////
+	if workshop.Spec.Hibernate.Enabled {
+		// Keep the countdown of the schedule running while hibernated
+		result, err := r.hibernateWorkshop(workshop, users, appsHostnameSuffix)
+		return util.EarliestRequeue(result, requeueResult), err
+	}
+	if result, err := r.resumeWorkshop(workshop, users); util.IsRequeued(result, err) {
+		return result, err
+	}
+	if result, err := r.restoreProjectAccess(workshop); util.IsRequeued(result, err) {
+		return result, err
+	}
+
 	//////////////////////////
 	// Portal
 	//////////////////////////
@@ -165,14 +239,14 @@ func (r *WorkshopReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	//////////////////////////
 	// Nexus
 	//////////////////////////
-	if result, err := r.reconcileNexus(workshop); util.IsRequeued(result, err) {
+	if result, err := r.reconcileNexus(workshop, users, appsHostnameSuffix); util.IsRequeued(result, err) {
 		return result, err
 	}
 
 	//////////////////////////
 	// Gitea
 	//////////////////////////
-	if result, err := r.reconcileGitea(workshop, users); util.IsRequeued(result, err) {
+	if result, err := r.reconcileGitea(workshop, users, appsHostnameSuffix); util.IsRequeued(result, err) {
 		return result, err
 	}
 
@@ -200,7 +274,7 @@ func (r *WorkshopReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	//////////////////////////
 	// Service Mesh
 	//////////////////////////
-	if result, err := r.reconcileServiceMesh(workshop, users); util.IsRequeued(result, err) {
+	if result, err := r.reconcileServiceMesh(workshop, users, appsHostnameSuffix); util.IsRequeued(result, err) {
 		return result, err
 	}
 
@@ -232,11 +306,49 @@ func (r *WorkshopReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return result, err
 	}
 
-	return ctrl.Result{}, nil
+	//////////////////////////
+	// Seed
+	//////////////////////////
+	// Manifests fetched from git are refreshed periodically
+	seedResult, err := r.reconcileSeed(workshop, users, appsHostnameSuffix)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return util.EarliestRequeue(requeueResult, seedResult), nil
 }
 
 func (r *WorkshopReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&workshopv1.Workshop{}).
+		// The status updates of the operator do not trigger a new reconciliation
+		For(&workshopv1.Workshop{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Vault pods come back sealed after a restart
+		Watches(&source.Kind{Type: &appsv1.StatefulSet{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.vaultToWorkshops)}).
 		Complete(r)
 }
+
+// vaultToWorkshops maps a Vault StatefulSet to the Workshops deploying Vault in its namespace
+func (r *WorkshopReconciler) vaultToWorkshops(object handler.MapObject) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	if object.Meta.GetLabels()["app.kubernetes.io/part-of"] != "vault" {
+		return requests
+	}
+
+	workshops := &workshopv1.WorkshopList{}
+	if err := r.List(context.TODO(), workshops); err != nil {
+		log.Errorf("Failed to list Workshops: %s", err)
+		return requests
+	}
+
+	for _, workshop := range workshops.Items {
+		if workshop.Status.Namespaces.Vault != object.Meta.GetNamespace() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: workshop.Name, Namespace: workshop.Namespace},
+		})
+	}
+	return requests
+}